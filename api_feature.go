@@ -3,12 +3,18 @@ package componenttest
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ONSdigital/dp-authorisation/v2/authorisationtest"
@@ -37,8 +43,47 @@ type APIFeature struct {
 	HealthCheckInterval  time.Duration
 	ExpectedResponseTime time.Duration
 	JWTFeature           *JWTFeature
+	// Variables holds values captured during the scenario which can be referenced as "{{name}}" in later steps
+	Variables *ScenarioVariables
+	// BaseURL, when set, sends requests over HTTPClient to a running service instead of calling the Initialiser's handler
+	BaseURL    string
+	HTTPClient *http.Client
+	// OpenAPIContract, when set, validates every request and response against an OpenAPI document
+	OpenAPIContract              *OpenAPIContract
+	skipOpenAPIContract          bool
+	skipOpenAPIRequestValidation bool
+	// Polling configures the backoff of the polling steps, using the defaults if nil
+	Polling *PollingOptions
+	// Snapshots, when set, holds the golden files that responses can be compared against
+	Snapshots *SnapshotStore
+	// ConcurrentResponses holds every response to the last concurrent requests, in the order the requests were created
+	ConcurrentResponses []*http.Response
+	// PersistCookies keeps the cookies set by responses in a cookie jar and sends them with later requests in the scenario
+	PersistCookies bool
+	requestCookies map[string]string
+	cookies        *cookiejar.Jar
+	// FollowRedirects follows redirects to the same host, as the "redirects are followed" step does for a scenario
+	FollowRedirects  bool
+	followRedirects  bool
+	redirects        []redirect
+	stream           *responseStream
+	responseEncoding responseEncoding
+	// Latencies, when set, records how long every request takes so that slow requests can be reported
+	Latencies *LatencyRecorder
+	// ResponseTime is how long the last request took to be answered, including any redirects followed
+	ResponseTime      time.Duration
+	latencyBudget     time.Duration
+	dynamicValidators dynamicValidatorRegistry
 }
 
+// APIClientOptions are optional configuration options for an APIFeature targeting a running service
+type APIClientOptions struct {
+	Timeout   time.Duration
+	Transport http.RoundTripper
+}
+
+const defaultAPIClientTimeout = 30 * time.Second
+
 // HealthCheckTest represents a test healthcheck struct that mimics the real healthcheck struct
 type HealthCheckTest struct {
 	Status    string                  `json:"status"`
@@ -66,6 +111,49 @@ func NewAPIFeature(initialiser ServiceInitialiser) *APIFeature {
 		requestHeaders: make(map[string]string),
 		StartTime:      time.Now(),
 		JWTFeature:     NewJWTFeature(),
+		Variables:      NewScenarioVariables(),
+	}
+}
+
+// NewAPIFeatureWithBaseURL returns a new APIFeature that sends requests over a real HTTP connection to the service
+// listening at baseURL, e.g. "http://localhost:10000". If no options are supplied a default timeout and transport are used
+func NewAPIFeatureWithBaseURL(baseURL string, opts *APIClientOptions) *APIFeature {
+	// the defaults are set on a copy so that options shared between features are left as the caller set them
+	var options APIClientOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	if options.Timeout == 0 {
+		options.Timeout = defaultAPIClientTimeout
+	}
+
+	if options.Transport == nil {
+		options.Transport = http.DefaultTransport
+	}
+
+	// an *http.Transport asks for gzip and decodes the response itself unless a request sets Accept-Encoding, which
+	// would hide the content coding from the compression steps, so only the encodings a scenario asks for are sent
+	if transport, ok := options.Transport.(*http.Transport); ok {
+		transport = transport.Clone()
+		transport.DisableCompression = true
+		options.Transport = transport
+	}
+
+	return &APIFeature{
+		BaseURL:        strings.TrimSuffix(baseURL, "/"),
+		requestHeaders: make(map[string]string),
+		StartTime:      time.Now(),
+		JWTFeature:     NewJWTFeature(),
+		Variables:      NewScenarioVariables(),
+		HTTPClient: &http.Client{
+			Timeout:   options.Timeout,
+			Transport: options.Transport,
+			// redirects are returned as-is to match the behaviour of the in-process handler
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
 	}
 }
 
@@ -74,10 +162,21 @@ func NewAPIFeatureWithHandler(handler http.Handler) *APIFeature {
 	return NewAPIFeature(StaticHandler(handler))
 }
 
-// Reset the request headers
+// Reset the request headers, cookies, scenario variables, concurrent responses, redirects, streams, latency budget
+// and OpenAPI contract enforcement
 func (f *APIFeature) Reset() {
 	f.ErrorFeature.Reset()
+	f.closeStream()
+	f.latencyBudget = 0
 	f.requestHeaders = make(map[string]string)
+	f.requestCookies = nil
+	f.cookies = nil
+	f.followRedirects = false
+	f.redirects = nil
+	f.Variables.Reset()
+	f.ConcurrentResponses = nil
+	f.skipOpenAPIContract = false
+	f.skipOpenAPIRequestValidation = false
 }
 
 // RegisterSteps binds the APIFeature steps to the godog context to enable usage in the component tests
@@ -90,21 +189,65 @@ func (f *APIFeature) RegisterSteps(ctx *godog.ScenarioContext) {
 	ctx.Step(`^I PUT "([^"]*)"$`, f.IPut)
 	ctx.Step(`^I PATCH "([^"]*)"$`, f.IPatch)
 	ctx.Step(`^I DELETE "([^"]*)"`, f.IDelete)
+	ctx.Step(`^I (POST|PUT|PATCH) "([^"]*)" with the body from file "([^"]*)"$`, f.IRequestWithTheBodyFromFile)
+	ctx.Step(`^I (POST|PUT|PATCH) "([^"]*)" with the form:$`, f.IRequestWithTheForm)
+	ctx.Step(`^I GET "([^"]*)" with the query parameters:$`, f.IGetWithTheQueryParameters)
+	ctx.Step(`^I GET "([^"]*)" until the JSON path "([^"]*)" is "([^"]*)" within (\d+) seconds?$`, f.IGetUntilTheJSONPathIs)
+	ctx.Step(`^I GET "([^"]*)" until the HTTP status code is "?(\d+)"? within (\d+) seconds?$`, f.IGetUntilTheHTTPStatusCodeIs)
+	ctx.Step(`^I (POST|PUT|PATCH) "([^"]*)" with the multipart form:$`, f.IRequestWithTheMultipartForm)
+	ctx.Step(`^I send (\d+) concurrent (GET|POST|PUT|PATCH|DELETE) requests to "([^"]*)"$`, f.ISendConcurrentRequests)
+	ctx.Step(`^I send (\d+) concurrent (POST|PUT|PATCH) requests to "([^"]*)" with body:$`, f.ISendConcurrentRequestsWithBody)
+	ctx.Step(`^exactly (\d+ responses? (?:has|have) status "?\d+"?(?:,? and \d+ (?:has|have) status "?\d+"?)*)$`, f.ExactlyResponsesHaveStatus)
+	ctx.Step(`^I use the first concurrent response with status "?(\d+)"?$`, f.IUseTheFirstConcurrentResponseWithStatus)
+	ctx.Step(`^I set the cookie "([^"]*)" to "([^"]*)"$`, f.ISetTheCookieTo)
+	ctx.Step(`^I clear the cookies$`, f.IClearTheCookies)
+	ctx.Step(`^I GET "([^"]*)" as a stream$`, f.IGetAsAStream)
 	ctx.Step(`^I am an admin user$`, f.adminJWTToken)
 	ctx.Step(`^I am a publisher user$`, f.publisherJWTToken)
 	ctx.Step(`^I am not authenticated$`, f.iAmNotAuthenticated)
 	ctx.Step(`^the HTTP status code should be "([^"]*)"$`, f.TheHTTPStatusCodeShouldBe)
 	ctx.Step(`^the response header "([^"]*)" should be "([^"]*)"$`, f.TheResponseHeaderShouldBe)
+	ctx.Step(`^the response should be compressed with "([^"]*)"$`, f.TheResponseShouldBeCompressedWith)
+	ctx.Step(`^the response should not be compressed$`, f.TheResponseShouldNotBeCompressed)
+	ctx.Step(`^the (compressed|uncompressed) response body should be at most (\d+ ?(?:B|KB|MB)?)$`, f.TheResponseBodyShouldBeAtMost)
+	ctx.Step(`^the response cookie "([^"]*)" should be "([^"]*)"$`, f.TheResponseCookieShouldBe)
+	ctx.Step(`^the response cookie "([^"]*)" should have the following attributes:$`, f.TheResponseCookieShouldHaveTheFollowingAttributes)
 	ctx.Step(`^I should receive the following response:$`, f.IShouldReceiveTheFollowingResponse)
 	ctx.Step(`^I have a healthcheck interval of (\d+) seconds?$`, f.iHaveAHealthCheckIntervalOfSecond)
 	ctx.Step(`^the health checks should have completed within (\d+) seconds?$`, f.theHealthChecksShouldHaveCompletedWithinSeconds)
 	ctx.Step(`^I should receive the following health JSON response:$`, f.iShouldReceiveTheFollowingHealthJSONResponse)
+	ctx.Step(`^the "([^"]*)" endpoint should report "([^"]*)" within (\d+) seconds?$`, f.TheEndpointShouldReportWithin)
+	ctx.Step(`^the "([^"]*)" endpoint should report "([^"]*)" for check "([^"]*)" within (\d+) seconds?$`, f.TheEndpointShouldReportForCheckWithin)
 	ctx.Step(`^I should receive the following JSON response:$`, f.IShouldReceiveTheFollowingJSONResponse)
 	ctx.Step(`^I should receive the following JSON response with status "([^"]*)":$`, f.IShouldReceiveTheFollowingJSONResponseWithStatus)
+	ctx.Step(`^I should receive the following JSON response in any order:$`, f.IShouldReceiveTheFollowingJSONResponseInAnyOrder)
+	ctx.Step(`^the response should contain the following JSON:$`, f.TheResponseShouldContainTheFollowingJSON)
+	ctx.Step(`^the response should contain the following JSON in any order:$`, f.TheResponseShouldContainTheFollowingJSONInAnyOrder)
 	ctx.Step(`^I use a service auth token "([^"]*)"$`, f.IUseAServiceAuthToken)
 	ctx.Step(`^I use an X Florence user token "([^"]*)"$`, f.IUseAnXFlorenceUserToken)
 	ctx.Step(`^I wait (\d+) seconds`, f.delayTimeBySeconds)
 	ctx.Step(`^I am a JWT user with email "([^"]*)" and group "([^"]*)"$`, f.IUseAJWTToken)
+	ctx.Step(`^I store the JSON path "([^"]*)" as "([^"]*)"$`, f.IStoreTheJSONPathAs)
+	ctx.Step(`^I store the response header "([^"]*)" as "([^"]*)"$`, f.IStoreTheResponseHeaderAs)
+	ctx.Step(`^the JSON path "([^"]*)" should be "([^"]*)"$`, f.TheJSONPathShouldBe)
+	ctx.Step(`^the JSON path "([^"]*)" should exist$`, f.TheJSONPathShouldExist)
+	ctx.Step(`^the JSON path "([^"]*)" should not exist$`, f.TheJSONPathShouldNotExist)
+	ctx.Step(`^the JSON path "([^"]*)" should have length (\d+)$`, f.TheJSONPathShouldHaveLength)
+	ctx.Step(`^the JSON path "([^"]*)" should match the regex "(.*)"$`, f.TheJSONPathShouldMatchTheRegex)
+	ctx.Step(`^the response should conform to the JSON schema "([^"]*)"$`, f.TheResponseShouldConformToTheJSONSchema)
+	ctx.Step(`^the response should conform to the following JSON schema:$`, f.TheResponseShouldConformToTheFollowingJSONSchema)
+	ctx.Step(`^requests are not validated against the OpenAPI contract$`, f.requestsAreNotValidatedAgainstTheOpenAPIContract)
+	ctx.Step(`^the OpenAPI contract is not enforced$`, f.theOpenAPIContractIsNotEnforced)
+	ctx.Step(`^I should receive the following lines within (\d+) seconds?:$`, f.IShouldReceiveTheFollowingLinesWithin)
+	ctx.Step(`^I should receive the following events within (\d+) seconds?:$`, f.IShouldReceiveTheFollowingEventsWithin)
+	ctx.Step(`^the stream should complete within (\d+) seconds?$`, f.TheStreamShouldCompleteWithin)
+	ctx.Step(`^the stream should have been flushed before it completed$`, f.TheStreamShouldHaveBeenFlushedBeforeItCompleted)
+	ctx.Step(`^the response should have been received within (\d+) (milliseconds|seconds?)$`, f.TheResponseShouldHaveBeenReceivedWithin)
+	ctx.Step(`^every response should be received within (\d+) (milliseconds|seconds?)$`, f.everyResponseShouldBeReceivedWithin)
+	ctx.Step(`^redirects are followed$`, f.redirectsAreFollowed)
+	ctx.Step(`^I should be redirected to "([^"]*)" with status "?(\d+)"?$`, f.IShouldBeRedirectedToWithStatus)
+	ctx.Step(`^the redirect chain should be:$`, f.TheRedirectChainShouldBe)
+	ctx.Step(`^the response should match the snapshot "([^"]*)"$`, f.TheResponseShouldMatchTheSnapshot)
 }
 
 func (f *APIFeature) adminJWTToken() error {
@@ -167,17 +310,17 @@ func (f *APIFeature) IGet(path string) error {
 
 // IPostToWithBody makes a POST request to the provided path with the current headers and the body provided
 func (f *APIFeature) IPostToWithBody(path string, body *godog.DocString) error {
-	return f.makeRequest("POST", path, []byte(body.Content))
+	return f.makeRequestWithDocString("POST", path, body)
 }
 
 // IPut makes a PUT request to the provided path with the current headers and the body provided
 func (f *APIFeature) IPut(path string, body *godog.DocString) error {
-	return f.makeRequest("PUT", path, []byte(body.Content))
+	return f.makeRequestWithDocString("PUT", path, body)
 }
 
 // IPatch makes a PATCH request to the provided path with the current headers and the body provided
 func (f *APIFeature) IPatch(path string, body *godog.DocString) error {
-	return f.makeRequest("PATCH", path, []byte(body.Content))
+	return f.makeRequestWithDocString("PATCH", path, body)
 }
 
 // IDelete makes a DELETE request to the provided path with the current headers
@@ -185,52 +328,274 @@ func (f *APIFeature) IDelete(path string) error {
 	return f.makeRequest("DELETE", path, nil)
 }
 
+// makeRequestWithDocString makes a request with the body provided, replacing any scenario variable references
+func (f *APIFeature) makeRequestWithDocString(method, path string, body *godog.DocString) error {
+	content, err := f.Variables.Interpolate(body.Content)
+	if err != nil {
+		return err
+	}
+	return f.makeRequest(method, path, []byte(content))
+}
+
 func (f *APIFeature) makeRequest(method, path string, data []byte) error {
-	handler, err := f.Initialiser()
+	return f.makeRequestWithHeaders(method, path, data, nil)
+}
+
+// makeRequestWithHeaders makes a request with the current headers and headers, which are set for this request only
+// and take precedence over the current headers. Redirects are recorded and, if enabled, followed.
+func (f *APIFeature) makeRequestWithHeaders(method, path string, data []byte, headers map[string]string) error {
+	path, err := f.Variables.Interpolate(path)
 	if err != nil {
 		return err
 	}
-	req := httptest.NewRequest(method, "http://foo"+path, bytes.NewReader(data))
+
+	f.redirects = nil
+	f.ResponseTime = 0
+	visited := []string{method + " " + path}
+	for {
+		req, err := f.sendRequestWithHeaders(method, path, data, headers)
+		if err != nil {
+			return err
+		}
+
+		redirect, ok := f.recordRedirect(req)
+		if !ok || !f.followingRedirects() {
+			return nil
+		}
+
+		nextMethod, nextPath, ok := redirect.target(req)
+		if !ok {
+			return nil
+		}
+		if nextMethod != method {
+			data, headers = nil, nil
+		}
+		method, path = nextMethod, f.pathFromBaseURL(nextPath)
+
+		if visited, err = checkRedirectLoop(visited, method+" "+path); err != nil {
+			return err
+		}
+	}
+}
+
+// sendRequestWithHeaders makes a single request, keeping its response in HTTPResponse
+func (f *APIFeature) sendRequestWithHeaders(method, path string, data []byte, headers map[string]string) (*http.Request, error) {
+	req, err := f.newRequest(method, path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	resp, err := f.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	duration := time.Since(start)
+	f.ResponseTime += duration
+	f.recordLatency(req, duration)
+
+	if f.responseEncoding, err = decodeResponseBody(resp); err != nil {
+		return nil, err
+	}
+	if err := f.storeResponseCookies(req, resp); err != nil {
+		return nil, err
+	}
+
+	f.HTTPResponse = resp
+	if err := f.validateOpenAPIContract(req, data); err != nil {
+		return nil, err
+	}
+	return req, f.checkLatencyBudget(req)
+}
+
+// newRequest creates a request with the current headers and cookies, addressed to BaseURL if set
+func (f *APIFeature) newRequest(method, path string, data []byte) (*http.Request, error) {
+	var req *http.Request
+	if f.BaseURL != "" {
+		var err error
+		req, err = http.NewRequest(method, f.BaseURL+path, bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+	} else {
+		req = httptest.NewRequest(method, "http://foo"+path, bytes.NewReader(data))
+	}
+
+	if err := f.setRequestHeaders(req); err != nil {
+		return nil, err
+	}
+	if err := f.setRequestCookies(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// doRequest serves the request with the Initialiser's handler or, if BaseURL is set, sends it to the
+// running service
+func (f *APIFeature) doRequest(req *http.Request) (*http.Response, error) {
+	do, err := f.requestDoer()
+	if err != nil {
+		return nil, err
+	}
+	return do(req)
+}
+
+// requestDoer returns a function that serves requests with the Initialiser's handler or, if BaseURL is set, sends
+// them to the running service. The handler is retrieved once, so the function can make several requests, including
+// concurrently.
+func (f *APIFeature) requestDoer() (func(*http.Request) (*http.Response, error), error) {
+	if f.BaseURL != "" {
+		return f.sendRequest, nil
+	}
+
+	handler, err := f.Initialiser()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Result(), nil
+	}, nil
+}
+
+// sendRequest sends the request to the running service. The response body is read in full so that the connection
+// is released and the body can be asserted on in the same way as an in-process response.
+func (f *APIFeature) sendRequest(req *http.Request) (*http.Response, error) {
+	resp, err := f.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make %s request to %s: %w", req.Method, req.URL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+// setRequestHeaders sets the current request headers on req, replacing any scenario variable references
+func (f *APIFeature) setRequestHeaders(req *http.Request) error {
 	for key, value := range f.requestHeaders {
+		value, err := f.Variables.Interpolate(value)
+		if err != nil {
+			return err
+		}
 		req.Header.Set(key, value)
 	}
+	return nil
+}
+
+// readResponseBody reads the body of the last response and replaces it with an unread copy, so the body
+// can be read again by any following step
+func (f *APIFeature) readResponseBody() ([]byte, error) {
+	if f.HTTPResponse == nil {
+		return nil, fmt.Errorf("no response has been received")
+	}
+	return readBody(f.HTTPResponse)
+}
+
+// readBody reads the body of resp and replaces it with an unread copy
+func readBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
 
-	w := httptest.NewRecorder()
-	handler.ServeHTTP(w, req)
+// readResponseJSON reads and decodes the body of the last response as JSON. Numbers are decoded as json.Number so
+// that large integers keep their precision.
+func (f *APIFeature) readResponseJSON() (interface{}, error) {
+	body, err := f.readResponseBody()
+	if err != nil {
+		return nil, err
+	}
 
-	f.HTTPResponse = w.Result()
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body as JSON: %w", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("failed to unmarshal response body as JSON: unexpected data after the top-level value")
+	}
+	return document, nil
+}
+
+// IStoreTheJSONPathAs stores the value at the JSON path of the response body, e.g. "items[0].id", as a
+// scenario variable that can be referenced as "{{name}}" in later steps
+func (f *APIFeature) IStoreTheJSONPathAs(path, name string) error {
+	document, err := f.readResponseJSON()
+	if err != nil {
+		return err
+	}
+
+	value, err := lookupJSONPath(document, path)
+	if err != nil {
+		return err
+	}
+
+	str, err := jsonValueToString(value)
+	if err != nil {
+		return err
+	}
+
+	f.Variables.Set(name, str)
 	return nil
 }
 
-// IShouldReceiveTheFollowingResponse asserts the response body and expected response body are equal
-func (f *APIFeature) IShouldReceiveTheFollowingResponse(expectedAPIResponse *godog.DocString) error {
-	responseBody := f.HTTPResponse.Body
-	body, _ := io.ReadAll(responseBody)
+// IStoreTheResponseHeaderAs stores the value of a response header as a scenario variable that can be
+// referenced as "{{name}}" in later steps
+func (f *APIFeature) IStoreTheResponseHeaderAs(header, name string) error {
+	if f.HTTPResponse == nil {
+		return fmt.Errorf("no response has been received")
+	}
 
-	assert.Equal(f, strings.TrimSpace(expectedAPIResponse.Content), strings.TrimSpace(string(body)))
+	value := f.HTTPResponse.Header.Get(header)
+	if value == "" {
+		return fmt.Errorf("response header %q is not set", header)
+	}
 
-	return f.StepError()
+	f.Variables.Set(name, value)
+	return nil
 }
 
-// IShouldReceiveTheFollowingJSONResponse asserts that the response body and expected response body are equal.
-// This also validates any "{{DYNAMIC_TIMESTAMP}}" fields.
-func (f *APIFeature) IShouldReceiveTheFollowingJSONResponse(expectedAPIResponse *godog.DocString) error {
-	responseBody := f.HTTPResponse.Body
-	body, err := io.ReadAll(responseBody)
+// IShouldReceiveTheFollowingResponse asserts the response body and expected response body are equal
+func (f *APIFeature) IShouldReceiveTheFollowingResponse(expectedAPIResponse *godog.DocString) error {
+	body, err := f.readResponseBody()
 	if err != nil {
-		return fmt.Errorf("error reading response body: %w", err)
+		return err
 	}
 
-	actualValidated, expectedValidated, err := validateDynamicValues(string(body), expectedAPIResponse.Content)
+	expected, err := f.Variables.Interpolate(expectedAPIResponse.Content)
 	if err != nil {
 		return err
 	}
 
-	assert.JSONEq(f, expectedValidated, actualValidated)
+	assert.Equal(f, strings.TrimSpace(expected), strings.TrimSpace(string(body)))
 
 	return f.StepError()
 }
 
+// IShouldReceiveTheFollowingJSONResponse asserts that the response body and expected response body are equal.
+// This also validates any "{{DYNAMIC_TIMESTAMP}}" fields.
+func (f *APIFeature) IShouldReceiveTheFollowingJSONResponse(expectedAPIResponse *godog.DocString) error {
+	return f.assertJSONResponse(expectedAPIResponse.Content, jsonComparison{})
+}
+
 // TheHTTPStatusCodeShouldBe asserts that the status code of the response matches the expected code
 func (f *APIFeature) TheHTTPStatusCodeShouldBe(expectedCodeStr string) error {
 	expectedCode, err := strconv.Atoi(expectedCodeStr)
@@ -261,22 +626,40 @@ func (f *APIFeature) IShouldReceiveTheFollowingJSONResponseWithStatus(expectedCo
 
 // DynamicValidator represents a validator for dynamic placeholder values in JSON.
 // It contains a validation function to check if a value is valid and a placeholder
-// string to replace valid values with for comparison.
+// string to replace valid values with for comparison. Validators for parameterised
+// placeholders, e.g. "{{DYNAMIC_REGEX:^cpih[0-9]+$}}", set ParameterisedValidationFunc,
+// which is passed the parameter and returns an error if the parameter itself is invalid.
+// Validators for values that need not be strings, e.g. "{{DYNAMIC_INT}}", set
+// ValueValidationFunc or ParameterisedValueValidationFunc instead, which are passed the
+// value as decoded by encoding/json.
 type DynamicValidator struct {
-	ValidationFunc func(value string) bool
-	Placeholder    string
+	ValidationFunc                   func(value string) bool
+	ParameterisedValidationFunc      func(value, parameter string) (bool, error)
+	ValueValidationFunc              func(value interface{}) bool
+	ParameterisedValueValidationFunc func(value interface{}, parameter string) (bool, error)
+	Placeholder                      string
 }
 
-// dynamicValidators is a registry of all available dynamic validators, keyed by
-// their placeholder type (e.g., "TIMESTAMP", "UUID").
-var dynamicValidators = map[string]DynamicValidator{
+// errDynamicValueNotString is returned when a validator that only validates strings is given another type
+var errDynamicValueNotString = errors.New("value is not a string")
+
+// dynamicValidatorRegistry maps placeholder types, e.g. "UUID", to their validators
+type dynamicValidatorRegistry map[string]DynamicValidator
+
+// dynamicValidatorsMu guards dynamicValidators, which may be added to by RegisterDynamicValidator
+var dynamicValidatorsMu sync.RWMutex
+
+// dynamicValidators is the default registry of dynamic validators available to every
+// APIFeature, keyed by their placeholder type (e.g., "TIMESTAMP", "UUID").
+var dynamicValidators = dynamicValidatorRegistry{
 	"TIMESTAMP": {
 		ValidationFunc: validator.ValidateTimestamp,
 		Placeholder:    "VALID_TIMESTAMP",
 	},
 	"RECENT_TIMESTAMP": {
-		ValidationFunc: validator.ValidateRecentTimestamp,
-		Placeholder:    "VALID_RECENT_TIMESTAMP",
+		ValidationFunc:              validator.ValidateRecentTimestamp,
+		ParameterisedValidationFunc: validateRecentTimestampWithin,
+		Placeholder:                 "VALID_RECENT_TIMESTAMP",
 	},
 	"URI_PATH": {
 		ValidationFunc: validator.ValidateURIPath,
@@ -290,12 +673,193 @@ var dynamicValidators = map[string]DynamicValidator{
 		ValidationFunc: validator.ValidateUUID,
 		Placeholder:    "VALID_UUID",
 	},
+	"REGEX": {
+		ParameterisedValidationFunc: validateRegex,
+		Placeholder:                 "VALID_REGEX",
+	},
+	"ONE_OF": {
+		ParameterisedValidationFunc: validateOneOf,
+		Placeholder:                 "VALID_ONE_OF",
+	},
+	"ANY": {
+		ValueValidationFunc: validator.ValidateAny,
+		Placeholder:         "VALID_ANY",
+	},
+	"NOT_NULL": {
+		ValueValidationFunc: validator.ValidateNotNull,
+		Placeholder:         "VALID_NOT_NULL",
+	},
+	"INT": {
+		ValueValidationFunc:              validator.ValidateInt,
+		ParameterisedValueValidationFunc: validateIntInRange,
+		Placeholder:                      "VALID_INT",
+	},
+	"NUMBER": {
+		ValueValidationFunc:              validator.ValidateNumber,
+		ParameterisedValueValidationFunc: validateNumberInRange,
+		Placeholder:                      "VALID_NUMBER",
+	},
+	"ARRAY_LEN": {
+		ParameterisedValueValidationFunc: validateArrayLength,
+		Placeholder:                      "VALID_ARRAY_LEN",
+	},
+}
+
+// dynamicValidationTypeRegex matches the names that custom validation types may be registered under
+var dynamicValidationTypeRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// RegisterDynamicValidator adds a validator to the defaults available to every APIFeature, so that
+// "{{DYNAMIC_<validationType>}}" can be used in expected responses. Registering an existing type
+// replaces its validator. If no Placeholder is given, "VALID_<validationType>" is used.
+func RegisterDynamicValidator(validationType string, v DynamicValidator) error {
+	v, err := checkDynamicValidator(validationType, v)
+	if err != nil {
+		return err
+	}
+
+	dynamicValidatorsMu.Lock()
+	defer dynamicValidatorsMu.Unlock()
+
+	dynamicValidators[validationType] = v
+	return nil
+}
+
+// RegisterDynamicValidator adds a validator that is only available to this APIFeature, taking precedence
+// over any default validator of the same type. Validators are kept when the feature is Reset.
+func (f *APIFeature) RegisterDynamicValidator(validationType string, v DynamicValidator) error {
+	v, err := checkDynamicValidator(validationType, v)
+	if err != nil {
+		return err
+	}
+
+	if f.dynamicValidators == nil {
+		f.dynamicValidators = make(dynamicValidatorRegistry)
+	}
+	f.dynamicValidators[validationType] = v
+	return nil
+}
+
+// checkDynamicValidator checks that a validator can be registered under validationType and fills in
+// its default placeholder
+func checkDynamicValidator(validationType string, v DynamicValidator) (DynamicValidator, error) {
+	if !dynamicValidationTypeRegex.MatchString(validationType) {
+		return v, fmt.Errorf("invalid dynamic validation type %q: must be upper case letters, digits and underscores", validationType)
+	}
+
+	if v.ValidationFunc == nil && v.ParameterisedValidationFunc == nil &&
+		v.ValueValidationFunc == nil && v.ParameterisedValueValidationFunc == nil {
+		return v, fmt.Errorf("dynamic validator %q has no validation function", validationType)
+	}
+
+	if v.Placeholder == "" {
+		v.Placeholder = "VALID_" + validationType
+	}
+	return v, nil
+}
+
+// dynamicValidatorRegistry returns the validators available to the feature: the defaults overridden by
+// any registered on the feature
+func (f *APIFeature) dynamicValidatorRegistry() dynamicValidatorRegistry {
+	return defaultDynamicValidatorsWith(f.dynamicValidators)
+}
+
+// defaultDynamicValidatorsWith returns the default validators overridden by those in registered
+func defaultDynamicValidatorsWith(registered dynamicValidatorRegistry) dynamicValidatorRegistry {
+	dynamicValidatorsMu.RLock()
+	defer dynamicValidatorsMu.RUnlock()
+
+	registry := make(dynamicValidatorRegistry, len(dynamicValidators)+len(registered))
+	for validationType, v := range dynamicValidators {
+		registry[validationType] = v
+	}
+	for validationType, v := range registered {
+		registry[validationType] = v
+	}
+	return registry
+}
+
+// validateRecentTimestampWithin validates "{{DYNAMIC_RECENT_TIMESTAMP:<max age>}}", e.g. "60s"
+func validateRecentTimestampWithin(value, maxAge string) (bool, error) {
+	age, err := time.ParseDuration(maxAge)
+	if err != nil || age <= 0 {
+		return false, fmt.Errorf("invalid maximum age %q: must be a positive duration, e.g. 60s", maxAge)
+	}
+
+	return validator.ValidateRecentTimestampWithin(value, age), nil
+}
+
+// validateRegex validates "{{DYNAMIC_REGEX:<expression>}}"
+func validateRegex(value, expression string) (bool, error) {
+	re, err := regexp.Compile(expression)
+	if err != nil {
+		return false, fmt.Errorf("invalid regex %q: %w", expression, err)
+	}
+
+	return re.MatchString(value), nil
+}
+
+// validateOneOf validates "{{DYNAMIC_ONE_OF:<option>|<option>...}}"
+func validateOneOf(value, options string) (bool, error) {
+	return validator.ValidateOneOf(value, strings.Split(options, "|")), nil
+}
+
+// validateIntInRange validates "{{DYNAMIC_INT:<min>..<max>}}"
+func validateIntInRange(value interface{}, numberRange string) (bool, error) {
+	if !validator.ValidateInt(value) {
+		return false, nil
+	}
+	return validateNumberInRange(value, numberRange)
+}
+
+// validateNumberInRange validates "{{DYNAMIC_NUMBER:<min>..<max>}}"
+func validateNumberInRange(value interface{}, numberRange string) (bool, error) {
+	minimum, maximum, err := parseNumberRange(numberRange)
+	if err != nil {
+		return false, err
+	}
+
+	return validator.ValidateNumberInRange(value, minimum, maximum), nil
+}
+
+// parseNumberRange parses an inclusive range written "<min>..<max>", where either bound may be omitted
+func parseNumberRange(numberRange string) (minimum, maximum float64, err error) {
+	lower, upper, ok := strings.Cut(numberRange, "..")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid range %q: must be <min>..<max>, e.g. 1..100", numberRange)
+	}
+
+	minimum, maximum = math.Inf(-1), math.Inf(1)
+	if lower != "" {
+		if minimum, err = strconv.ParseFloat(lower, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q: bad minimum %q", numberRange, lower)
+		}
+	}
+	if upper != "" {
+		if maximum, err = strconv.ParseFloat(upper, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q: bad maximum %q", numberRange, upper)
+		}
+	}
+
+	if minimum > maximum {
+		return 0, 0, fmt.Errorf("invalid range %q: minimum is greater than maximum", numberRange)
+	}
+	return minimum, maximum, nil
+}
+
+// validateArrayLength validates "{{DYNAMIC_ARRAY_LEN:<length>}}"
+func validateArrayLength(value interface{}, length string) (bool, error) {
+	expected, err := strconv.Atoi(length)
+	if err != nil || expected < 0 {
+		return false, fmt.Errorf("invalid array length %q", length)
+	}
+
+	return validator.ValidateArrayLength(value, expected), nil
 }
 
 // validateDynamicValues checks for any fields in expected with dynamic value
 // strings, e.g. "{{DYNAMIC_TIMESTAMP}}", validates them and replaces them
 // with a placeholder.
-func validateDynamicValues(actual, expected string) (actualValidated, expectedValidated string, err error) {
+func (r dynamicValidatorRegistry) validateDynamicValues(actual, expected string) (actualValidated, expectedValidated string, err error) {
 	var actualJSON, expectedJSON interface{}
 
 	if err := json.Unmarshal([]byte(actual), &actualJSON); err != nil {
@@ -306,7 +870,7 @@ func validateDynamicValues(actual, expected string) (actualValidated, expectedVa
 	}
 
 	// Single pass validation and replacement
-	if err := validateAndReplace(actualJSON, expectedJSON, ""); err != nil {
+	if err := r.validateAndReplace(actualJSON, expectedJSON, ""); err != nil {
 		return "", "", err
 	}
 
@@ -326,14 +890,14 @@ func validateDynamicValues(actual, expected string) (actualValidated, expectedVa
 // validateAndReplace traverses actual and expected JSON structures, validating
 // dynamic placeholders and replacing them with normalized values.
 // This handles strings, objects, arrays, or any other type
-func validateAndReplace(actual, expected interface{}, path string) error {
+func (r dynamicValidatorRegistry) validateAndReplace(actual, expected interface{}, path string) error {
 	switch exp := expected.(type) {
 	case map[string]interface{}:
-		return validateObject(actual, exp, path)
+		return r.validateObject(actual, exp, path)
 	case []interface{}:
-		return validateArray(actual, exp, path)
+		return r.validateArray(actual, exp, path)
 	case string:
-		return validateString(actual, exp, path)
+		return r.validateString(actual, exp, path)
 	case float64, bool, nil:
 		return nil
 	default:
@@ -341,12 +905,12 @@ func validateAndReplace(actual, expected interface{}, path string) error {
 	}
 }
 
-// validateObject validates that actual is an object matching the structure of
-// expected, checking all fields and recursing into nested structures.
-func validateObject(actual interface{}, expected map[string]interface{}, path string) error {
+// validateObject validates the fields of expected that are also in actual, recursing into nested structures.
+// Missing fields and type mismatches are left for diffJSON to report.
+func (r dynamicValidatorRegistry) validateObject(actual interface{}, expected map[string]interface{}, path string) error {
 	act, ok := actual.(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("type mismatch at %s: expected object, got %T", path, actual)
+		return nil
 	}
 
 	for key, expValue := range expected {
@@ -354,80 +918,166 @@ func validateObject(actual interface{}, expected map[string]interface{}, path st
 
 		actValue, exists := act[key]
 		if !exists {
-			return fmt.Errorf("missing field at %s", currentPath)
+			continue
 		}
 
-		if err := validateField(act, expected, key, actValue, expValue, currentPath); err != nil {
+		if err := r.validateField(act, expected, key, actValue, expValue, currentPath); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// validateArray validates that actual is an array with the same length and
-// structure as expected, validating each element and replacing dynamic values.
-func validateArray(actual interface{}, expected []interface{}, path string) error {
+// validateArray validates each element of expected against the element of actual at the same index, replacing
+// dynamic values. Arrays of different lengths are validated with validateUnequalArrays instead, and type mismatches
+// are left for diffJSON to report.
+func (r dynamicValidatorRegistry) validateArray(actual interface{}, expected []interface{}, path string) error {
 	act, ok := actual.([]interface{})
 	if !ok {
-		return fmt.Errorf("type mismatch at %s: expected array, got %T", path, actual)
+		return nil
 	}
 
 	if len(act) != len(expected) {
-		return fmt.Errorf("array length mismatch at %s: expected %d, got %d",
-			path, len(expected), len(act))
+		r.validateUnequalArrays(act, expected, path)
+		return nil
 	}
 
 	for i := range expected {
-		currentPath := buildPath(path, fmt.Sprintf("[%d]", i))
+		if err := r.validateElement(act, expected, i, i, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateElement validates the expected element at expIndex against the actual element at actIndex, replacing
+// dynamic values in both
+func (r dynamicValidatorRegistry) validateElement(act, expected []interface{}, actIndex, expIndex int, path string) error {
+	currentPath := buildPath(path, fmt.Sprintf("[%d]", expIndex))
+
+	// Handle string elements with dynamic placeholders
+	if expStr, ok := expected[expIndex].(string); ok && strings.HasPrefix(expStr, "{{DYNAMIC_") {
+		if err := r.validateDynamicValue(act[actIndex], expStr, currentPath); err != nil {
+			return err
+		}
 
-		// Handle string elements with dynamic placeholders
-		if expStr, ok := expected[i].(string); ok && strings.HasPrefix(expStr, "{{DYNAMIC_") {
-			if err := validateDynamicValue(act[i], expStr, currentPath); err != nil {
-				return err
+		placeholder := r.placeholder(expStr)
+		act[actIndex] = placeholder
+		expected[expIndex] = placeholder
+		return nil
+	}
+
+	// Recurse for non-dynamic values
+	return r.validateAndReplace(act[actIndex], expected[expIndex], currentPath)
+}
+
+// validateUnequalArrays pairs each expected element that contains a dynamic value, in order, with the most similar
+// later actual element of the same type that it validates against, and replaces the dynamic values of the pair.
+// Elements that cannot be paired are left unchanged, so that diffJSON reports them as missing or unexpected elements.
+func (r dynamicValidatorRegistry) validateUnequalArrays(act, expected []interface{}, path string) {
+	next := 0
+	for expIndex := range expected {
+		if !containsDynamicValue(expected[expIndex]) {
+			continue
+		}
+
+		best, bestScore := -1, -1
+		for actIndex := next; actIndex < len(act); actIndex++ {
+			if reflect.TypeOf(act[actIndex]) != reflect.TypeOf(expected[expIndex]) && !isDynamicValue(expected[expIndex]) {
+				continue
 			}
 
-			v := dynamicValidators[extractValidationType(expStr)]
-			act[i] = v.Placeholder
-			expected[i] = v.Placeholder
-		} else {
-			// Recurse for non-dynamic values
-			if err := validateAndReplace(act[i], expected[i], currentPath); err != nil {
-				return err
+			actCopy, expCopy := []interface{}{cloneJSON(act[actIndex])}, []interface{}{cloneJSON(expected[expIndex])}
+			if r.validateElement(actCopy, expCopy, 0, 0, path) != nil {
+				continue
+			}
+			if score := jsonSimilarity(expCopy[0], actCopy[0]); score > bestScore {
+				best, bestScore = actIndex, score
 			}
 		}
+
+		if best >= 0 {
+			// the pair is known to be valid, so this only replaces its dynamic values
+			_ = r.validateElement(act, expected, best, expIndex, path)
+			next = best + 1
+		}
+	}
+}
+
+// isDynamicValue returns whether value is a dynamic placeholder, e.g. "{{DYNAMIC_UUID}}"
+func isDynamicValue(value interface{}) bool {
+	str, ok := value.(string)
+	return ok && strings.HasPrefix(str, "{{DYNAMIC_")
+}
+
+// containsDynamicValue returns whether value is, or contains, a dynamic placeholder
+func containsDynamicValue(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, field := range v {
+			if containsDynamicValue(field) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, element := range v {
+			if containsDynamicValue(element) {
+				return true
+			}
+		}
+	}
+	return isDynamicValue(value)
+}
+
+// cloneJSON returns a deep copy of a decoded JSON value
+func cloneJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			clone[key] = cloneJSON(field)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, element := range v {
+			clone[i] = cloneJSON(element)
+		}
+		return clone
+	default:
+		return v
 	}
-	return nil
 }
 
 // validateString validates a string value, which may be a dynamic placeholder
 // or a regular value.
-func validateString(actual interface{}, expected, path string) error {
+func (r dynamicValidatorRegistry) validateString(actual interface{}, expected, path string) error {
 	if !strings.HasPrefix(expected, "{{DYNAMIC_") {
 		return nil
 	}
 
-	return validateDynamicValue(actual, expected, path)
+	return r.validateDynamicValue(actual, expected, path)
 }
 
 // validateField validates a single field, either by checking dynamic placeholders
 // or recursing into nested structures.
-func validateField(act, exp map[string]interface{}, key string, actValue, expValue interface{}, path string) error {
+func (r dynamicValidatorRegistry) validateField(act, exp map[string]interface{}, key string, actValue, expValue interface{}, path string) error {
 	// Check if it's a dynamic placeholder
 	expStr, ok := expValue.(string)
 	if ok && strings.HasPrefix(expStr, "{{DYNAMIC_") {
-		if err := validateDynamicValue(actValue, expStr, path); err != nil {
+		if err := r.validateDynamicValue(actValue, expStr, path); err != nil {
 			return err
 		}
 
-		v := dynamicValidators[extractValidationType(expStr)]
-		act[key] = v.Placeholder
-		exp[key] = v.Placeholder
+		placeholder := r.placeholder(expStr)
+		act[key] = placeholder
+		exp[key] = placeholder
 		return nil
 	}
 
 	// Handle arrays with potential dynamic placeholders
 	if expArr, ok := expValue.([]interface{}); ok {
-		if err := validateArray(actValue, expArr, path); err != nil {
+		if err := r.validateArray(actValue, expArr, path); err != nil {
 			return err
 		}
 		return nil
@@ -435,35 +1085,75 @@ func validateField(act, exp map[string]interface{}, key string, actValue, expVal
 
 	// Handle nested objects
 	if shouldRecurse(expValue) {
-		return validateAndReplace(actValue, expValue, path)
+		return r.validateAndReplace(actValue, expValue, path)
 	}
 
 	return nil
 }
 
 // validateDynamicValue validates that a value matches its dynamic placeholder
-// validator. It checks that the actual value is a string and passes the
-// validator's validation function.
-func validateDynamicValue(actual interface{}, expected, path string) error {
-	validationType := extractValidationType(expected)
+// validator. It checks that the actual value passes the validator's validation
+// function, given the placeholder's parameter if it has one.
+func (r dynamicValidatorRegistry) validateDynamicValue(actual interface{}, expected, path string) error {
+	validationType, parameter, hasParameter := extractValidationType(expected)
 
-	v, exists := dynamicValidators[validationType]
+	v, exists := r[validationType]
 	if !exists {
 		return fmt.Errorf("unknown validation type: %s", validationType)
 	}
 
-	actStr, ok := actual.(string)
-	if !ok {
+	if hasParameter && v.ParameterisedValidationFunc == nil && v.ParameterisedValueValidationFunc == nil {
+		return fmt.Errorf("validation type %s does not take a parameter", validationType)
+	}
+	if !hasParameter && v.ValidationFunc == nil && v.ValueValidationFunc == nil {
+		return fmt.Errorf("validation type %s requires a parameter, e.g. {{DYNAMIC_%s:...}}", validationType, validationType)
+	}
+
+	valid, err := v.validate(actual, parameter, hasParameter)
+	if errors.Is(err, errDynamicValueNotString) {
 		return fmt.Errorf("field at %s is not a string", path)
 	}
+	if err != nil {
+		return fmt.Errorf("invalid parameter for validation type %s: %w", validationType, err)
+	}
 
-	if !v.ValidationFunc(actStr) {
-		return fmt.Errorf("field at %s value %q is not valid", path, actStr)
+	if !valid {
+		actualStr, err := jsonValueToString(actual)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("field at %s value %q is not valid", path, actualStr)
 	}
 
 	return nil
 }
 
+// validate runs the validation function that applies to the value, preferring one that accepts any JSON type
+func (v DynamicValidator) validate(value interface{}, parameter string, hasParameter bool) (bool, error) {
+	if hasParameter && v.ParameterisedValueValidationFunc != nil {
+		return v.ParameterisedValueValidationFunc(value, parameter)
+	}
+	if !hasParameter && v.ValueValidationFunc != nil {
+		return v.ValueValidationFunc(value), nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return false, errDynamicValueNotString
+	}
+
+	if hasParameter {
+		return v.ParameterisedValidationFunc(str, parameter)
+	}
+	return v.ValidationFunc(str), nil
+}
+
+// placeholder returns the value that a validated dynamic placeholder and its actual value are replaced with
+func (r dynamicValidatorRegistry) placeholder(expected string) string {
+	validationType, _, _ := extractValidationType(expected)
+	return r[validationType].Placeholder
+}
+
 // buildPath constructs a JSON path string by appending a component to an
 // existing path, handling array indices appropriately.
 func buildPath(path, component string) string {
@@ -476,10 +1166,11 @@ func buildPath(path, component string) string {
 	return path + "." + component
 }
 
-// extractValidationType extracts the validation type from a dynamic placeholder
-// string (e.g., "{{DYNAMIC_TIMESTAMP}}" returns "TIMESTAMP").
-func extractValidationType(placeholder string) string {
-	return strings.TrimSuffix(strings.TrimPrefix(placeholder, "{{DYNAMIC_"), "}}")
+// extractValidationType extracts the validation type and any parameter from a dynamic placeholder
+// string (e.g., "{{DYNAMIC_TIMESTAMP}}" returns "TIMESTAMP", and "{{DYNAMIC_ONE_OF:a|b}}" returns
+// "ONE_OF" with the parameter "a|b").
+func extractValidationType(placeholder string) (validationType, parameter string, hasParameter bool) {
+	return strings.Cut(strings.TrimSuffix(strings.TrimPrefix(placeholder, "{{DYNAMIC_"), "}}"), ":")
 }
 
 // shouldRecurse determines whether validation should recurse into nested
@@ -507,31 +1198,51 @@ func (f *APIFeature) theHealthChecksShouldHaveCompletedWithinSeconds(expectedRes
 	return f.StepError()
 }
 
-// iShouldReceiveTheFollowingHealthJSONResponse asserts the health response and body match the expectation
+// iShouldReceiveTheFollowingHealthJSONResponse asserts the health response and body match the expectation.
+// Checks are matched by name, in any order, and only the fields given for an expected check are compared.
 func (f *APIFeature) iShouldReceiveTheFollowingHealthJSONResponse(expectedResponse *godog.DocString) error {
-	var healthResponse, expectedHealth HealthCheckTest
+	var expectedHealth HealthCheckTest
 
-	responseBody, err := io.ReadAll(f.HTTPResponse.Body)
+	healthResponse, err := f.readHealthResponse()
 	if err != nil {
-		return fmt.Errorf("failed to read health response - error: %v", err)
+		return err
 	}
 
-	err = json.Unmarshal(responseBody, &healthResponse)
+	err = json.Unmarshal([]byte(expectedResponse.Content), &expectedHealth)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal health response - error: %v", err)
+		return fmt.Errorf("failed to unmarshal expected health response - error: %v", err)
 	}
 
-	err = json.Unmarshal([]byte(expectedResponse.Content), &expectedHealth)
-	if err != nil {
+	// the fields given for each expected check, so that any that are left out are not compared
+	var expectedFields struct {
+		Checks []map[string]json.RawMessage `json:"checks"`
+	}
+	if err := json.Unmarshal([]byte(expectedResponse.Content), &expectedFields); err != nil {
 		return fmt.Errorf("failed to unmarshal expected health response - error: %v", err)
 	}
 
-	f.validateHealthCheckResponse(healthResponse, expectedHealth)
+	f.validateHealthCheckResponse(healthResponse, expectedHealth, expectedFields.Checks)
 
 	return f.StepError()
 }
 
-func (f *APIFeature) validateHealthCheckResponse(healthResponse, expectedResponse HealthCheckTest) {
+// readHealthResponse reads and decodes the body of the last response as a health check response
+func (f *APIFeature) readHealthResponse() (HealthCheckTest, error) {
+	var healthResponse HealthCheckTest
+
+	responseBody, err := f.readResponseBody()
+	if err != nil {
+		return healthResponse, fmt.Errorf("failed to read health response - error: %v", err)
+	}
+
+	err = json.Unmarshal(responseBody, &healthResponse)
+	if err != nil {
+		return healthResponse, fmt.Errorf("failed to unmarshal health response - error: %v", err)
+	}
+	return healthResponse, nil
+}
+
+func (f *APIFeature) validateHealthCheckResponse(healthResponse, expectedResponse HealthCheckTest, expectedFields []map[string]json.RawMessage) {
 	maxExpectedStartTime := f.StartTime.Add((f.HealthCheckInterval)).Add(1 * time.Second)
 
 	assert.Equal(&f.ErrorFeature, expectedResponse.Status, healthResponse.Status, "health response status should match expected")
@@ -539,10 +1250,7 @@ func (f *APIFeature) validateHealthCheckResponse(healthResponse, expectedRespons
 	assert.Greater(&f.ErrorFeature, healthResponse.Uptime.Seconds(), float64(0), "health response uptime should be greater than 0")
 
 	f.validateHealthVersion(healthResponse.Version, expectedResponse.Version, maxExpectedStartTime.UTC())
-
-	for i, checkResponse := range healthResponse.Checks {
-		f.validateHealthCheck(checkResponse, expectedResponse.Checks[i])
-	}
+	f.validateHealthChecks(healthResponse.Checks, expectedResponse.Checks, expectedFields)
 }
 
 func (f *APIFeature) validateHealthVersion(versionResponse, expectedVersion healthcheck.VersionInfo, maxExpectedStartTime time.Time) {
@@ -553,30 +1261,86 @@ func (f *APIFeature) validateHealthVersion(versionResponse, expectedVersion heal
 	assert.Equal(&f.ErrorFeature, expectedVersion.Version, versionResponse.Version, "version should match expected")
 }
 
-func (f *APIFeature) validateHealthCheck(checkResponse, expectedCheck *Check) {
+// validateHealthChecks matches the checks in the response to the expected checks by name, reporting any check that
+// is missing from the response or that is not expected
+func (f *APIFeature) validateHealthChecks(checks, expectedChecks []*Check, expectedFields []map[string]json.RawMessage) {
+	indexes := make(map[string]int)
+	for i, check := range checks {
+		if _, duplicate := indexes[check.Name]; duplicate {
+			f.Errorf("health check %q is in the response more than once", check.Name)
+			continue
+		}
+		indexes[check.Name] = i
+	}
+
+	expectedNames := make(map[string]bool)
+	for i, expectedCheck := range expectedChecks {
+		if expectedCheck.Name == "" {
+			f.Errorf("expected health check %d has no name", i)
+			continue
+		}
+		expectedNames[expectedCheck.Name] = true
+
+		index, ok := indexes[expectedCheck.Name]
+		if !ok {
+			f.Errorf("health check %q is missing from the response", expectedCheck.Name)
+			continue
+		}
+		f.validateHealthCheck(checks[index], expectedCheck, expectedFields[i], index)
+	}
+
+	for _, check := range checks {
+		if !expectedNames[check.Name] {
+			f.Errorf("unexpected health check %q in the response", check.Name)
+		}
+	}
+}
+
+// validateHealthCheck compares the fields given for the expected check. A literal message must match exactly, while a
+// "{{DYNAMIC_*}}" placeholder such as "{{DYNAMIC_REGEX:^connection refused}}" is validated against a message that varies.
+func (f *APIFeature) validateHealthCheck(checkResponse, expectedCheck *Check, expectedFields map[string]json.RawMessage, index int) {
 	maxExpectedHealthCheckTime := f.StartTime.Add(f.ExpectedResponseTime)
 	maxExpectedHealthCheckDiffSeconds := maxExpectedHealthCheckTime.Sub(f.StartTime).Seconds()
 
-	assert.Equal(&f.ErrorFeature, expectedCheck.Name, checkResponse.Name, "health check name should match expected")
-	assert.Equal(&f.ErrorFeature, expectedCheck.Status, checkResponse.Status, "health check status should match expected")
-	assert.Equal(&f.ErrorFeature, expectedCheck.StatusCode, checkResponse.StatusCode, "health check status code should match expected")
-	// This is a not empty to avoid dynamic content such as error details causing test failures
-	assert.NotEmpty(&f.ErrorFeature, checkResponse.Message, "health check message should not be empty")
-	assert.True(&f.ErrorFeature, checkResponse.LastChecked.Before(maxExpectedHealthCheckTime.UTC()), "last checked should be before max expected health check time")
-	assert.True(&f.ErrorFeature, checkResponse.LastChecked.After(f.StartTime), "last checked should be after start time")
+	name := checkResponse.Name
+	expectedStatus := checkResponse.Status
+	if _, ok := expectedFields["status"]; ok {
+		expectedStatus = expectedCheck.Status
+		assert.Equal(&f.ErrorFeature, expectedCheck.Status, checkResponse.Status, fmt.Sprintf("health check %q status should match expected", name))
+	}
+	if _, ok := expectedFields["status_code"]; ok {
+		assert.Equal(&f.ErrorFeature, expectedCheck.StatusCode, checkResponse.StatusCode, fmt.Sprintf("health check %q status code should match expected", name))
+	}
+	if _, ok := expectedFields["message"]; ok {
+		if strings.HasPrefix(expectedCheck.Message, "{{DYNAMIC_") {
+			path := fmt.Sprintf("checks[%d].message", index)
+			if err := f.dynamicValidatorRegistry().validateDynamicValue(checkResponse.Message, expectedCheck.Message, path); err != nil {
+				f.Errorf("health check %q message: %v", name, err)
+			}
+		} else {
+			assert.Equal(&f.ErrorFeature, expectedCheck.Message, checkResponse.Message, fmt.Sprintf("health check %q message should match expected", name))
+		}
+	}
+
+	if lastChecked := checkResponse.LastChecked; lastChecked != nil {
+		assert.True(&f.ErrorFeature, lastChecked.Before(maxExpectedHealthCheckTime.UTC()), fmt.Sprintf("health check %q last checked should be before max expected health check time", name))
+		assert.True(&f.ErrorFeature, lastChecked.After(f.StartTime), fmt.Sprintf("health check %q last checked should be after start time", name))
+	} else {
+		assert.Fail(&f.ErrorFeature, fmt.Sprintf("health check %q: last checked should not be nil", name))
+	}
 
-	if expectedCheck.Status == healthcheck.StatusOK {
+	if expectedStatus == healthcheck.StatusOK {
 		lastSuccess := checkResponse.LastSuccess
 
 		if lastSuccess != nil {
-			assert.True(&f.ErrorFeature, lastSuccess.Before(maxExpectedHealthCheckTime.UTC()), fmt.Sprintf("last success should complete within %vs", maxExpectedHealthCheckDiffSeconds))
+			assert.True(&f.ErrorFeature, lastSuccess.Before(maxExpectedHealthCheckTime.UTC()), fmt.Sprintf("health check %q last success should complete within %vs", name, maxExpectedHealthCheckDiffSeconds))
 			assert.True(
 				&f.ErrorFeature,
 				lastSuccess.After(f.StartTime),
-				fmt.Sprintf("last success should complete within %vs - got %vs", maxExpectedHealthCheckDiffSeconds, lastSuccess.Sub(f.StartTime).Seconds()),
+				fmt.Sprintf("health check %q last success should complete within %vs - got %vs", name, maxExpectedHealthCheckDiffSeconds, lastSuccess.Sub(f.StartTime).Seconds()),
 			)
 		} else {
-			assert.Fail(&f.ErrorFeature, "if expected status is ok, last success should not be nil")
+			assert.Fail(&f.ErrorFeature, fmt.Sprintf("health check %q: if expected status is ok, last success should not be nil", name))
 		}
 	} else {
 		lastFailure := checkResponse.LastFailure
@@ -585,11 +1349,11 @@ func (f *APIFeature) validateHealthCheck(checkResponse, expectedCheck *Check) {
 			assert.True(
 				&f.ErrorFeature,
 				lastFailure.Before(maxExpectedHealthCheckTime.UTC()),
-				fmt.Sprintf("last failure should complete within %vs - got %vs", maxExpectedHealthCheckDiffSeconds, lastFailure.Sub(f.StartTime).Seconds()),
+				fmt.Sprintf("health check %q last failure should complete within %vs - got %vs", name, maxExpectedHealthCheckDiffSeconds, lastFailure.Sub(f.StartTime).Seconds()),
 			)
-			assert.True(&f.ErrorFeature, lastFailure.After(f.StartTime), "last failure should be after start time")
+			assert.True(&f.ErrorFeature, lastFailure.After(f.StartTime), fmt.Sprintf("health check %q last failure should be after start time", name))
 		} else {
-			assert.Fail(&f.ErrorFeature, "if expected status is not ok, last failure should not be nil")
+			assert.Fail(&f.ErrorFeature, fmt.Sprintf("health check %q: if expected status is not ok, last failure should not be nil", name))
 		}
 	}
 }
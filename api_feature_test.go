@@ -0,0 +1,48 @@
+package componenttest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateHealthChecks(t *testing.T) {
+	Convey("Given an APIFeature that started a minute ago", t, func() {
+		f := &APIFeature{StartTime: time.Now().Add(-time.Minute), ExpectedResponseTime: 2 * time.Minute}
+		lastChecked := time.Now()
+		fields := []map[string]json.RawMessage{{"name": nil, "message": nil}}
+
+		Convey("When a check has a different literal message", func() {
+			checks := []*Check{{Name: "Mongo", Status: "OK", Message: "mongo is unhealthy", LastChecked: &lastChecked, LastSuccess: &lastChecked}}
+			f.validateHealthChecks(checks, []*Check{{Name: "Mongo", Message: "mongo is healthy"}}, fields)
+
+			Convey("Then the message is reported", func() {
+				So(f.StepError(), ShouldNotBeNil)
+				So(f.StepError().Error(), ShouldContainSubstring, `health check "Mongo" message should match expected`)
+			})
+		})
+
+		Convey("When a check has the same literal message", func() {
+			checks := []*Check{{Name: "Mongo", Status: "OK", Message: "mongo is healthy", LastChecked: &lastChecked, LastSuccess: &lastChecked}}
+			f.validateHealthChecks(checks, []*Check{{Name: "Mongo", Message: "mongo is healthy"}}, fields)
+
+			Convey("Then there is no error", func() {
+				So(f.StepError(), ShouldBeNil)
+			})
+		})
+
+		Convey("When a check has no last checked time", func() {
+			checks := []*Check{{Name: "Mongo", Status: "OK", Message: "mongo is healthy", LastSuccess: &lastChecked}}
+
+			Convey("Then it is reported without panicking", func() {
+				So(func() {
+					f.validateHealthChecks(checks, []*Check{{Name: "Mongo", Message: "mongo is healthy"}}, fields)
+				}, ShouldNotPanic)
+				So(f.StepError(), ShouldNotBeNil)
+				So(f.StepError().Error(), ShouldContainSubstring, `health check "Mongo": last checked should not be nil`)
+			})
+		})
+	})
+}
@@ -18,6 +18,7 @@ func NewAuthorizationFeature() *AuthorizationFeature {
 		FakeAuthService:    httpfake.New(),
 		FakePermissionsAPI: setupFakePermissionsAPI(),
 	}
+	f.AuthServiceRequests = RecordRequests(f.FakeAuthService)
 
 	return f
 }
@@ -26,11 +27,14 @@ type AuthorizationFeature struct {
 	ErrorFeature
 	FakeAuthService    *httpfake.HTTPFake
 	FakePermissionsAPI *authorisationtest.FakePermissionsAPI
+	// AuthServiceRequests records the requests received by FakeAuthService
+	AuthServiceRequests *RequestRecorder
 }
 
 func (f *AuthorizationFeature) Reset() {
 	f.ErrorFeature.Reset()
 	f.FakeAuthService.Reset()
+	f.AuthServiceRequests.Reset()
 	f.FakePermissionsAPI.Reset()
 }
 
@@ -0,0 +1,194 @@
+package componenttest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cucumber/godog"
+)
+
+// statusCountPattern matches each "N has/have status CODE" clause of a status distribution, e.g. "1 response has
+// status 200 and 19 have status 409"
+var statusCountPattern = regexp.MustCompile(`(\d+) (?:responses? )?(?:has|have) status "?(\d+)"?`)
+
+// ISendConcurrentRequests makes count requests to the provided path in parallel, with the current headers. The
+// responses are kept in ConcurrentResponses rather than HTTPResponse, see IUseTheFirstConcurrentResponseWithStatus.
+func (f *APIFeature) ISendConcurrentRequests(count int, method, path string) error {
+	return f.sendConcurrentRequests(count, method, path, nil)
+}
+
+// ISendConcurrentRequestsWithBody makes count requests to the provided path in parallel, each with the current
+// headers and the body provided. The responses are kept in ConcurrentResponses rather than HTTPResponse.
+func (f *APIFeature) ISendConcurrentRequestsWithBody(count int, method, path string, body *godog.DocString) error {
+	content, err := f.Variables.Interpolate(body.Content)
+	if err != nil {
+		return err
+	}
+	return f.sendConcurrentRequests(count, method, path, []byte(content))
+}
+
+// sendConcurrentRequests creates every request up front and then releases them together, so that they reach the
+// service as close to simultaneously as possible
+func (f *APIFeature) sendConcurrentRequests(count int, method, path string, data []byte) error {
+	if count < 1 {
+		return fmt.Errorf("at least 1 concurrent request must be sent, got %d", count)
+	}
+
+	path, err := f.Variables.Interpolate(path)
+	if err != nil {
+		return err
+	}
+
+	do, err := f.requestDoer()
+	if err != nil {
+		return err
+	}
+
+	requests := make([]*http.Request, count)
+	for i := range requests {
+		if requests[i], err = f.newRequest(method, path, data); err != nil {
+			return err
+		}
+	}
+
+	responses := make([]*http.Response, count)
+	durations := make([]time.Duration, count)
+	errs := make([]error, count)
+	start := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			requestStart := time.Now()
+			if responses[i], errs[i] = do(req); errs[i] != nil {
+				errs[i] = fmt.Errorf("concurrent request %d: %w", i+1, errs[i])
+			}
+			durations[i] = time.Since(requestStart)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for i, resp := range responses {
+		f.recordLatency(requests[i], durations[i])
+		if _, err := decodeResponseBody(resp); err != nil {
+			return fmt.Errorf("concurrent request %d: %w", i+1, err)
+		}
+		if err := f.storeResponseCookies(requests[i], resp); err != nil {
+			return err
+		}
+	}
+
+	f.ConcurrentResponses = responses
+	return f.validateConcurrentOpenAPIContract(requests, data)
+}
+
+// validateConcurrentOpenAPIContract validates every concurrent request and its response against the OpenAPI contract
+func (f *APIFeature) validateConcurrentOpenAPIContract(requests []*http.Request, requestBody []byte) error {
+	if f.OpenAPIContract == nil || f.skipOpenAPIContract {
+		return nil
+	}
+
+	for i, resp := range f.ConcurrentResponses {
+		responseBody, err := readBody(resp)
+		if err != nil {
+			return err
+		}
+		if err := f.OpenAPIContract.validate(requests[i], requestBody, resp, responseBody, !f.skipOpenAPIRequestValidation); err != nil {
+			return fmt.Errorf("concurrent request %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ExactlyResponsesHaveStatus asserts how many of the concurrent responses have each status code, given as a
+// distribution such as "1 response has status 200 and 19 have status 409". Status codes that are not mentioned
+// are not checked.
+func (f *APIFeature) ExactlyResponsesHaveStatus(distribution string) error {
+	if len(f.ConcurrentResponses) == 0 {
+		return errors.New("no concurrent requests have been sent")
+	}
+
+	received := f.concurrentStatusCounts()
+
+	var errs []error
+	for _, match := range statusCountPattern.FindAllStringSubmatch(distribution, -1) {
+		expectedCount, err := strconv.Atoi(match[1])
+		if err != nil {
+			return err
+		}
+		status, err := strconv.Atoi(match[2])
+		if err != nil {
+			return err
+		}
+
+		if received[status] != expectedCount {
+			errs = append(errs, fmt.Errorf("expected exactly %d response(s) with status %d, got %d", expectedCount, status, received[status]))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w\nstatus codes received: %s", errors.Join(errs...), formatStatusCounts(received))
+	}
+	return nil
+}
+
+// IUseTheFirstConcurrentResponseWithStatus makes the first of the concurrent responses with the status code the
+// current response, so that it can be asserted on by the other steps
+func (f *APIFeature) IUseTheFirstConcurrentResponseWithStatus(expectedCodeStr string) error {
+	expectedCode, err := strconv.Atoi(expectedCodeStr)
+	if err != nil {
+		return err
+	}
+
+	for _, resp := range f.ConcurrentResponses {
+		if resp.StatusCode == expectedCode {
+			f.HTTPResponse = resp
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no concurrent response has status %d, status codes received: %s", expectedCode, formatStatusCounts(f.concurrentStatusCounts()))
+}
+
+// concurrentStatusCounts returns the number of concurrent responses with each status code
+func (f *APIFeature) concurrentStatusCounts() map[int]int {
+	counts := make(map[int]int)
+	for _, resp := range f.ConcurrentResponses {
+		counts[resp.StatusCode]++
+	}
+	return counts
+}
+
+// formatStatusCounts describes status code counts in ascending order of status code, e.g. "200 x1, 409 x19"
+func formatStatusCounts(counts map[int]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+
+	statuses := make([]int, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	descriptions := make([]string, len(statuses))
+	for i, status := range statuses {
+		descriptions[i] = fmt.Sprintf("%d x%d", status, counts[status])
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,240 @@
+package componenttest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cucumber/godog"
+)
+
+// cookieExpiryTolerance is how far a cookie's expiry may be from the expected expiry, to allow for the time
+// taken to make the request and assert on it
+const cookieExpiryTolerance = time.Minute
+
+// ISetTheCookieTo sets a cookie to be sent with the following requests in the scenario. It replaces any cookie
+// of the same name in the cookie jar.
+func (f *APIFeature) ISetTheCookieTo(name, value string) error {
+	if f.requestCookies == nil {
+		f.requestCookies = make(map[string]string)
+	}
+	f.requestCookies[name] = value
+	return nil
+}
+
+// IClearTheCookies removes the cookies set by ISetTheCookieTo and any kept in the cookie jar, e.g. to sign out
+func (f *APIFeature) IClearTheCookies() error {
+	f.requestCookies = nil
+	f.cookies = nil
+	return nil
+}
+
+// TheResponseCookieShouldBe asserts that the response sets the cookie to the expected value
+func (f *APIFeature) TheResponseCookieShouldBe(name, expectedValue string) error {
+	cookie, err := f.responseCookie(name)
+	if err != nil {
+		return err
+	}
+
+	expectedValue, err = f.Variables.Interpolate(expectedValue)
+	if err != nil {
+		return err
+	}
+
+	if cookie.Value != expectedValue {
+		return fmt.Errorf("expected response cookie %q to be %q, got %q", name, expectedValue, cookie.Value)
+	}
+	return nil
+}
+
+/*
+TheResponseCookieShouldHaveTheFollowingAttributes asserts that the response sets the cookie with the attributes
+in the table. Attributes that are not in the table are not checked.
+
+HttpOnly and Secure are "true" or "false". SameSite is "Default", "Lax", "Strict" or "None". Max-Age is a number
+of seconds. Expires is either "session", for a cookie without an expiry, or a duration from now such as "1h" or
+"720h", allowing a minute either way; it is met by a Max-Age as well as an Expires attribute.
+
+Table should look like:
+| attribute | value  |
+| HttpOnly  | true   |
+| Secure    | true   |
+| SameSite  | Strict |
+| Path      | /      |
+| Expires   | 1h     |
+*/
+func (f *APIFeature) TheResponseCookieShouldHaveTheFollowingAttributes(name string, table *godog.Table) error {
+	cookie, err := f.responseCookie(name)
+	if err != nil {
+		return err
+	}
+
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue
+		}
+		if len(row.Cells) != 2 {
+			return fmt.Errorf("table row %d has %d cells, expected an attribute and a value", i, len(row.Cells))
+		}
+
+		attribute, expected := row.Cells[0].Value, row.Cells[1].Value
+		actual, err := cookieAttribute(cookie, attribute)
+		if err != nil {
+			return err
+		}
+
+		if strings.EqualFold(attribute, "Expires") {
+			err = checkCookieExpiry(cookie, expected)
+		} else if !strings.EqualFold(actual, expected) {
+			err = fmt.Errorf("expected %s %q, got %q", attribute, expected, actual)
+		}
+		if err != nil {
+			f.Errorf("response cookie %q: %v", name, err)
+		}
+	}
+
+	return f.StepError()
+}
+
+// responseCookie returns the last cookie of the name set by the response
+func (f *APIFeature) responseCookie(name string) (*http.Cookie, error) {
+	if f.HTTPResponse == nil {
+		return nil, fmt.Errorf("no response has been received")
+	}
+
+	var found *http.Cookie
+	for _, cookie := range f.HTTPResponse.Cookies() {
+		if cookie.Name == name {
+			found = cookie
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("the response does not set the cookie %q", name)
+	}
+	return found, nil
+}
+
+// cookieAttribute returns the value of a cookie attribute as it is written in a step table
+func cookieAttribute(cookie *http.Cookie, attribute string) (string, error) {
+	switch strings.ToLower(attribute) {
+	case "httponly":
+		return strconv.FormatBool(cookie.HttpOnly), nil
+	case "secure":
+		return strconv.FormatBool(cookie.Secure), nil
+	case "samesite":
+		return sameSiteName(cookie.SameSite), nil
+	case "path":
+		return cookie.Path, nil
+	case "domain":
+		return cookie.Domain, nil
+	case "max-age":
+		return strconv.Itoa(cookie.MaxAge), nil
+	case "expires":
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown cookie attribute %q, expected HttpOnly, Secure, SameSite, Path, Domain, Max-Age or Expires", attribute)
+	}
+}
+
+func sameSiteName(sameSite http.SameSite) string {
+	switch sameSite {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return "Default"
+	}
+}
+
+// checkCookieExpiry checks that the cookie is a session cookie, if expected is "session", or otherwise that it
+// expires after the duration expected, within cookieExpiryTolerance
+func checkCookieExpiry(cookie *http.Cookie, expected string) error {
+	var expiry time.Time
+	switch {
+	case cookie.MaxAge > 0:
+		expiry = time.Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+	case cookie.MaxAge < 0:
+		expiry = time.Unix(0, 0)
+	case !cookie.Expires.IsZero():
+		expiry = cookie.Expires
+	}
+
+	if strings.EqualFold(expected, "session") {
+		if !expiry.IsZero() {
+			return fmt.Errorf("expected a session cookie, got one that expires at %s", expiry.UTC().Format(time.RFC1123))
+		}
+		return nil
+	}
+
+	duration, err := time.ParseDuration(expected)
+	if err != nil {
+		return fmt.Errorf("invalid expiry %q, expected \"session\" or a duration such as 1h", expected)
+	}
+	if expiry.IsZero() {
+		return fmt.Errorf("expected the cookie to expire in %v, got a session cookie", duration)
+	}
+
+	expectedExpiry := time.Now().Add(duration)
+	if difference := expiry.Sub(expectedExpiry).Abs(); difference > cookieExpiryTolerance {
+		return fmt.Errorf("expected the cookie to expire in %v, at about %s, got %s", duration,
+			expectedExpiry.UTC().Format(time.RFC1123), expiry.UTC().Format(time.RFC1123))
+	}
+	return nil
+}
+
+// setRequestCookies adds the cookies set by ISetTheCookieTo to req, along with any in the cookie jar that are
+// not replaced by them
+func (f *APIFeature) setRequestCookies(req *http.Request) error {
+	for name, value := range f.requestCookies {
+		value, err := f.Variables.Interpolate(value)
+		if err != nil {
+			return err
+		}
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+
+	if f.cookies == nil {
+		return nil
+	}
+	for _, cookie := range f.cookies.Cookies(f.cookieURL(req)) {
+		if _, replaced := f.requestCookies[cookie.Name]; !replaced {
+			req.AddCookie(cookie)
+		}
+	}
+	return nil
+}
+
+// storeResponseCookies keeps the cookies set by resp in the cookie jar, if PersistCookies is set
+func (f *APIFeature) storeResponseCookies(req *http.Request, resp *http.Response) error {
+	if !f.PersistCookies {
+		return nil
+	}
+
+	if f.cookies == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return fmt.Errorf("failed to create cookie jar: %w", err)
+		}
+		f.cookies = jar
+	}
+
+	f.cookies.SetCookies(f.cookieURL(req), resp.Cookies())
+	return nil
+}
+
+// cookieURL returns the URL that cookies are stored against for req. Requests served in-process are treated as
+// secure, as the handler cannot tell whether it is behind TLS, so that Secure cookies are sent back to it.
+func (f *APIFeature) cookieURL(req *http.Request) *url.URL {
+	u := *req.URL
+	if f.BaseURL == "" {
+		u.Scheme = "https"
+	}
+	return &u
+}
@@ -0,0 +1,143 @@
+package componenttest
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// responseEncoding describes the Content-Encoding of the last response and the size of its body before and after
+// decoding
+type responseEncoding struct {
+	// encodings are the content codings of the body, in the order they were applied
+	encodings   []string
+	encodedSize int
+	decodedSize int
+}
+
+// decoders decode the content codings that response bodies are transparently decoded from
+var decoders = map[string]func(io.Reader) (io.Reader, error){
+	"gzip": func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": decodeDeflate,
+	"br": func(r io.Reader) (io.Reader, error) {
+		return brotli.NewReader(r), nil
+	},
+}
+
+// decodeDeflate decodes a deflate body, which should be zlib wrapped but is sent as raw deflate by some servers
+func decodeDeflate(r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		return zr, nil
+	}
+	return flate.NewReader(bytes.NewReader(data)), nil
+}
+
+// decodeResponseBody replaces a gzip, deflate or br encoded body of resp with the decoded body, leaving its headers
+// as they were received. A body with any other content coding is left encoded.
+func decodeResponseBody(resp *http.Response) (responseEncoding, error) {
+	body, err := readBody(resp)
+	if err != nil {
+		return responseEncoding{}, err
+	}
+
+	encoding := responseEncoding{encodedSize: len(body), decodedSize: len(body)}
+	for _, coding := range strings.Split(resp.Header.Get("Content-Encoding"), ",") {
+		if coding = strings.ToLower(strings.TrimSpace(coding)); coding != "" && coding != "identity" {
+			encoding.encodings = append(encoding.encodings, coding)
+		}
+	}
+
+	for _, coding := range encoding.encodings {
+		if _, ok := decoders[coding]; !ok {
+			return encoding, nil
+		}
+	}
+
+	decoded := body
+	for i := len(encoding.encodings) - 1; i >= 0; i-- {
+		coding := encoding.encodings[i]
+
+		reader, err := decoders[coding](bytes.NewReader(decoded))
+		if err != nil {
+			return encoding, fmt.Errorf("failed to decode %s response body: %w", coding, err)
+		}
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return encoding, fmt.Errorf("failed to decode %s response body: %w", coding, err)
+		}
+	}
+
+	if len(encoding.encodings) > 0 {
+		resp.Body = io.NopCloser(bytes.NewReader(decoded))
+		resp.ContentLength = int64(len(decoded))
+		resp.Uncompressed = true
+	}
+	encoding.decodedSize = len(decoded)
+	return encoding, nil
+}
+
+// TheResponseShouldBeCompressedWith asserts that the response body was encoded with the content coding, e.g. gzip,
+// deflate or br
+func (f *APIFeature) TheResponseShouldBeCompressedWith(expectedEncoding string) error {
+	if f.HTTPResponse == nil {
+		return fmt.Errorf("no response has been received")
+	}
+
+	encodings := f.responseEncoding.encodings
+	if len(encodings) == 0 {
+		return fmt.Errorf("expected the response to be compressed with %s, but it was not compressed", expectedEncoding)
+	}
+	if actual := strings.Join(encodings, ", "); !strings.EqualFold(actual, expectedEncoding) {
+		return fmt.Errorf("expected the response to be compressed with %s, but it was compressed with %s", expectedEncoding, actual)
+	}
+	return nil
+}
+
+// TheResponseShouldNotBeCompressed asserts that the response body was not encoded
+func (f *APIFeature) TheResponseShouldNotBeCompressed() error {
+	if f.HTTPResponse == nil {
+		return fmt.Errorf("no response has been received")
+	}
+
+	if encodings := f.responseEncoding.encodings; len(encodings) > 0 {
+		return fmt.Errorf("expected the response not to be compressed, but it was compressed with %s", strings.Join(encodings, ", "))
+	}
+	return nil
+}
+
+// TheResponseBodyShouldBeAtMost asserts that the size of the response body, as it was received if compressed is
+// "compressed" or once decoded otherwise, is within the budget, e.g. "512", "64KB" or "5MB"
+func (f *APIFeature) TheResponseBodyShouldBeAtMost(compressed, budget string) error {
+	if f.HTTPResponse == nil {
+		return fmt.Errorf("no response has been received")
+	}
+
+	maxSize, err := parseByteSize(budget)
+	if err != nil {
+		return err
+	}
+
+	size := f.responseEncoding.decodedSize
+	if compressed == "compressed" {
+		size = f.responseEncoding.encodedSize
+	}
+
+	if size > maxSize {
+		return fmt.Errorf("expected the %s response body to be at most %d bytes, got %d bytes (%d compressed, %d uncompressed)",
+			compressed, maxSize, size, f.responseEncoding.encodedSize, f.responseEncoding.decodedSize)
+	}
+	return nil
+}
@@ -1,14 +1,26 @@
 package componenttest
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 )
 
+// errFailNow is the panic value of FailNow when no failure has been recorded
+var errFailNow = errors.New("FailNow called")
+
+// ErrorFeature collects the assertion failures made against it, e.g. by testify's assert and require
+// packages, so that a step can return all of them together from StepError
 type ErrorFeature struct {
 	testing.TB
 	// composing testing.TB allows ErrorFeature to be passed off as a testing.T thingy
-	err error
+	errs     []error
+	failed   bool
+	cleanups []func()
 }
 
 func (t *ErrorFeature) Log(_ ...interface{}) {
@@ -16,19 +28,106 @@ func (t *ErrorFeature) Log(_ ...interface{}) {
 }
 
 func (t *ErrorFeature) Logf(format string, args ...interface{}) {
-	t.err = fmt.Errorf(format, args...)
+	t.addError(fmt.Sprintf(format, args...))
+}
+
+func (t *ErrorFeature) Error(args ...interface{}) {
+	t.addError(fmt.Sprint(args...))
 }
 
 func (t *ErrorFeature) Errorf(format string, args ...interface{}) {
-	t.err = fmt.Errorf(format, args...)
+	t.addError(fmt.Sprintf(format, args...))
+}
+
+// Fatal records a failure and stops the step, see FailNow
+func (t *ErrorFeature) Fatal(args ...interface{}) {
+	t.addError(fmt.Sprint(args...))
+	t.FailNow()
+}
+
+// Fatalf records a failure and stops the step, see FailNow
+func (t *ErrorFeature) Fatalf(format string, args ...interface{}) {
+	t.addError(fmt.Sprintf(format, args...))
+	t.FailNow()
 }
 
+// Fail marks the step as failed without recording a message
+func (t *ErrorFeature) Fail() {
+	t.failed = true
+}
+
+// FailNow stops the step by panicking with the failures recorded so far, which godog reports as the
+// step's error. It must only be called from the goroutine running the step.
+func (t *ErrorFeature) FailNow() {
+	err := t.StepError()
+	if err == nil {
+		err = errFailNow
+	}
+
+	t.failed = true
+	panic(err)
+}
+
+func (t *ErrorFeature) Failed() bool {
+	return t.failed || len(t.errs) > 0
+}
+
+// Cleanup registers a function to be called when the ErrorFeature is next Reset. Functions are called
+// in the reverse order to which they were registered.
+func (t *ErrorFeature) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+// TempDir returns a new temporary directory which is removed when the ErrorFeature is next Reset
+func (t *ErrorFeature) TempDir() string {
+	dir, err := os.MkdirTemp("", "component-test")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+	return dir
+}
+
+// Setenv sets an environment variable which is restored when the ErrorFeature is next Reset
+func (t *ErrorFeature) Setenv(key, value string) {
+	previous, exists := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatalf("Setenv: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if exists {
+			_ = os.Setenv(key, previous)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+// StepError returns every failure recorded since the last Reset, joined together, or nil if there are none
 func (t *ErrorFeature) StepError() error {
-	return t.err
+	if len(t.errs) == 0 {
+		if t.failed {
+			return errors.New("step failed")
+		}
+		return nil
+	}
+
+	return errors.Join(t.errs...)
 }
 
+// Reset clears any recorded failures and runs the functions registered with Cleanup
 func (t *ErrorFeature) Reset() {
-	t.err = nil
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+
+	t.cleanups = nil
+	t.errs = nil
+	t.failed = false
 }
 
 func (t *ErrorFeature) Helper() {
@@ -38,3 +137,31 @@ func (t *ErrorFeature) Helper() {
 func (t *ErrorFeature) Name() string {
 	return "Component Test"
 }
+
+// addError records a failure along with the location it was made from
+func (t *ErrorFeature) addError(message string) {
+	if location := callerLocation(); location != "" {
+		message = location + ": " + message
+	}
+	t.errs = append(t.errs, errors.New(message))
+}
+
+// callerLocation returns the file and line of the first caller outside of ErrorFeature and testify,
+// i.e. the assertion that failed
+func callerLocation() string {
+	pcs := make([]uintptr, 20)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" &&
+			!strings.Contains(frame.Function, ".(*ErrorFeature).") &&
+			!strings.HasPrefix(frame.Function, "github.com/stretchr/testify/") &&
+			frame.File != "<autogenerated>" {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package componenttest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runStep calls step as godog would, returning the error that a FailNow panic stopped it with
+func runStep(step func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+	return step()
+}
+
+func TestErrorFeatureStepError(t *testing.T) {
+	Convey("Given an ErrorFeature", t, func() {
+		f := &ErrorFeature{}
+
+		Convey("When no assertion fails", func() {
+			assert.Equal(f, 1, 1)
+
+			Convey("Then there is no step error", func() {
+				So(f.Failed(), ShouldBeFalse)
+				So(f.StepError(), ShouldBeNil)
+			})
+		})
+
+		Convey("When several assertions fail", func() {
+			assert.Equal(f, "expected one", "actual one")
+			assert.True(f, false, "second failure")
+			f.Errorf("third failure: %d", 3)
+
+			Convey("Then every failure is joined in the step error", func() {
+				So(f.Failed(), ShouldBeTrue)
+
+				err := f.StepError()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "expected one")
+				So(err.Error(), ShouldContainSubstring, "second failure")
+				So(err.Error(), ShouldContainSubstring, "third failure: 3")
+			})
+
+			Convey("Then Reset clears the failures", func() {
+				f.Reset()
+				So(f.Failed(), ShouldBeFalse)
+				So(f.StepError(), ShouldBeNil)
+			})
+		})
+
+		Convey("When an assertion fails", func() {
+			assert.Equal(f, "expected", "actual")
+			_, _, line, _ := runtime.Caller(0)
+
+			Convey("Then the failure starts with the file and line of the assertion", func() {
+				So(f.StepError().Error(), ShouldStartWith, fmt.Sprintf("error_feature_test.go:%d: ", line-1))
+			})
+		})
+
+		Convey("When a failure is recorded directly", func() {
+			f.Errorf("direct failure")
+			_, _, line, _ := runtime.Caller(0)
+
+			Convey("Then the failure starts with the file and line it was recorded from", func() {
+				So(f.StepError().Error(), ShouldEqual, fmt.Sprintf("error_feature_test.go:%d: direct failure", line-1))
+			})
+		})
+
+		Convey("When the step is failed without a message", func() {
+			f.Fail()
+
+			Convey("Then the step error says the step failed", func() {
+				So(f.StepError(), ShouldNotBeNil)
+				So(f.StepError().Error(), ShouldEqual, "step failed")
+			})
+		})
+	})
+}
+
+func TestErrorFeatureFailNow(t *testing.T) {
+	Convey("Given an ErrorFeature with a failed assertion", t, func() {
+		f := &ErrorFeature{}
+		assert.Equal(f, "first", "other")
+
+		Convey("When a require assertion fails in a step", func() {
+			reachedEnd := false
+			err := runStep(func() error {
+				require.Equal(f, "second", "other")
+				reachedEnd = true
+				return f.StepError()
+			})
+
+			Convey("Then the step is stopped with every failure collected so far", func() {
+				So(reachedEnd, ShouldBeFalse)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "first")
+				So(err.Error(), ShouldContainSubstring, "second")
+			})
+		})
+	})
+
+	Convey("Given an ErrorFeature without failures", t, func() {
+		f := &ErrorFeature{}
+
+		Convey("When FailNow is called in a step", func() {
+			err := runStep(func() error {
+				f.FailNow()
+				return nil
+			})
+
+			Convey("Then the step is stopped with errFailNow", func() {
+				So(errors.Is(err, errFailNow), ShouldBeTrue)
+				So(f.Failed(), ShouldBeTrue)
+			})
+		})
+
+		Convey("When Fatalf is called in a step", func() {
+			err := runStep(func() error {
+				f.Fatalf("fatal %s", "failure")
+				return nil
+			})
+
+			Convey("Then the step is stopped with the failure", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEndWith, ": fatal failure")
+			})
+		})
+	})
+}
+
+func TestErrorFeatureCleanup(t *testing.T) {
+	Convey("Given an ErrorFeature with several cleanups", t, func() {
+		f := &ErrorFeature{}
+
+		var calls []int
+		for i := 1; i <= 3; i++ {
+			f.Cleanup(func() {
+				calls = append(calls, i)
+			})
+		}
+
+		Convey("When it is Reset", func() {
+			f.Reset()
+
+			Convey("Then the cleanups are run in the reverse order to which they were registered", func() {
+				So(calls, ShouldResemble, []int{3, 2, 1})
+			})
+
+			Convey("Then the cleanups are not run again by the next Reset", func() {
+				f.Reset()
+				So(calls, ShouldResemble, []int{3, 2, 1})
+			})
+		})
+	})
+
+	Convey("Given an ErrorFeature with an environment variable set", t, func() {
+		const unset, existing = "COMPONENT_TEST_UNSET_VARIABLE", "COMPONENT_TEST_EXISTING_VARIABLE"
+		So(os.Unsetenv(unset), ShouldBeNil)
+		So(os.Setenv(existing, "original"), ShouldBeNil)
+		defer os.Unsetenv(existing)
+
+		f := &ErrorFeature{}
+		f.Setenv(unset, "value")
+		f.Setenv(existing, "changed")
+
+		So(os.Getenv(unset), ShouldEqual, "value")
+		So(os.Getenv(existing), ShouldEqual, "changed")
+
+		Convey("When it is Reset", func() {
+			f.Reset()
+
+			Convey("Then the environment variables are restored", func() {
+				_, exists := os.LookupEnv(unset)
+				So(exists, ShouldBeFalse)
+				So(os.Getenv(existing), ShouldEqual, "original")
+			})
+		})
+	})
+
+	Convey("Given an ErrorFeature with a temporary directory", t, func() {
+		f := &ErrorFeature{}
+		dir := f.TempDir()
+
+		_, err := os.Stat(dir)
+		So(err, ShouldBeNil)
+
+		Convey("When it is Reset", func() {
+			f.Reset()
+
+			Convey("Then the directory is removed", func() {
+				_, err := os.Stat(dir)
+				So(os.IsNotExist(err), ShouldBeTrue)
+			})
+		})
+	})
+}
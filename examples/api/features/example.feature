@@ -8,6 +8,7 @@ Feature: Example feature
         """
 
     Scenario: Example 2 endpoint scenario
+        Given I set the "Content-Type" header to "text/plain"
         When I POST "/example2"
         """
         foo bar
@@ -26,7 +27,7 @@ Feature: Example feature
         And I should receive the following health JSON response:
           """
             {
-              "status": "OK",
+              "status": "WARNING",
               "version": {
                 "git_commit": "6584b786caac36b6214ffe04bf62f058d4021538",
                 "language": "go",
@@ -34,6 +35,11 @@ Feature: Example feature
                 "version": "v1.2.3"
               },
               "checks": [
+                {
+                  "name": "Mongo",
+                  "status": "WARNING",
+                  "message": "{{DYNAMIC_REGEX:^mongo ping failed after [0-9]+ms$}}"
+                },
                 {
                   "name": "Redis",
                   "status": "OK",
@@ -114,4 +120,488 @@ Feature: Example feature
           }
         ]
         """
-      
\ No newline at end of file
+
+    Scenario: Values captured from a response are used in later steps
+        Given I set the "Content-Type" header to "application/json"
+        When I POST "/datasets"
+        """
+        {"title": "CPIH"}
+        """
+        Then the HTTP status code should be "201"
+        And I store the JSON path "id" as "datasetID"
+        And I store the response header "Location" as "datasetLocation"
+        When I set the "X-Dataset-ID" header to "{{datasetID}}"
+        And I GET "{{datasetLocation}}"
+        Then I should receive the following JSON response with status "200":
+        """
+        {"id": "{{datasetID}}", "title": "CPIH"}
+        """
+
+    Scenario: JSON path assertions on a response
+        When I GET "/dynamic/validation/object"
+        Then the HTTP status code should be "200"
+        And the JSON path "id" should be "{{DYNAMIC_UUID}}"
+        And the JSON path "items[1].id" should exist
+        And the JSON path "items[2]" should not exist
+        And the JSON path "embedded.missing" should not exist
+        And the JSON path "items" should have length 2
+        And the JSON path "uri_path" should match the regex "^/endpoint/[0-9a-f-]+$"
+        When I GET "/example1"
+        Then the JSON path "example_type" should be "1"
+
+    Scenario: Response conforms to a JSON schema
+        When I GET "/dynamic/validation/object"
+        Then the HTTP status code should be "200"
+        And the response should conform to the JSON schema "features/schemas/dynamic_response.json"
+        And the response should conform to the following JSON schema:
+        """
+        {
+          "type": "object",
+          "required": ["id", "items"],
+          "properties": {
+            "id": { "type": "string", "format": "uuid" },
+            "items": {
+              "type": "array",
+              "minItems": 2,
+              "items": { "$ref": "features/schemas/embedded_item.json" }
+            }
+          }
+        }
+        """
+
+    Scenario: Requests that deliberately break the OpenAPI contract
+        Given requests are not validated against the OpenAPI contract
+        And I set the "Content-Type" header to "application/json"
+        When I POST "/datasets"
+        """
+        not json
+        """
+        Then the HTTP status code should be "400"
+
+    Scenario: Paths outside of the OpenAPI contract
+        Given the OpenAPI contract is not enforced
+        When I GET "/unknown"
+        Then the HTTP status code should be "404"
+
+    Scenario: Lenient comparison of a list with an unstable order
+        Given I set the "Content-Type" header to "application/json"
+        And I POST "/datasets"
+        """
+        {"title": "CPIH"}
+        """
+        And I POST "/datasets"
+        """
+        {"title": "CPI"}
+        """
+        When I GET "/datasets"
+        Then the response should contain the following JSON in any order:
+        """
+        {
+          "items": [
+            {"id": "{{DYNAMIC_UUID}}", "title": "CPI"},
+            {"id": "{{DYNAMIC_UUID}}", "title": "CPIH"}
+          ]
+        }
+        """
+        And the response should contain the following JSON:
+        """
+        {"items": []}
+        """
+
+    Scenario: Comparison of an array ignoring the order of its elements
+        When I GET "/dynamic/validation/array"
+        Then I should receive the following JSON response in any order:
+        """
+        [
+          {
+            "timestamp": "{{DYNAMIC_RECENT_TIMESTAMP}}",
+            "id": "{{DYNAMIC_UUID}}",
+            "embedded": {"inner_timestamp": "{{DYNAMIC_RECENT_TIMESTAMP}}"},
+            "items": [
+              {"id": "{{DYNAMIC_UUID}}", "last_updated": "{{DYNAMIC_RECENT_TIMESTAMP}}"},
+              {"id": "{{DYNAMIC_UUID}}", "last_updated": "{{DYNAMIC_RECENT_TIMESTAMP}}"}
+            ],
+            "uri_path": "{{DYNAMIC_URI_PATH}}",
+            "url": "{{DYNAMIC_URL}}"
+          },
+          {
+            "timestamp": "{{DYNAMIC_RECENT_TIMESTAMP}}",
+            "id": "{{DYNAMIC_UUID}}",
+            "embedded": {"inner_timestamp": "{{DYNAMIC_RECENT_TIMESTAMP}}"},
+            "items": [
+              {"id": "{{DYNAMIC_UUID}}", "last_updated": "{{DYNAMIC_RECENT_TIMESTAMP}}"},
+              {"id": "{{DYNAMIC_UUID}}", "last_updated": "{{DYNAMIC_RECENT_TIMESTAMP}}"}
+            ],
+            "uri_path": "{{DYNAMIC_URI_PATH}}",
+            "url": "{{DYNAMIC_URL}}"
+          }
+        ]
+        """
+
+    Scenario: Custom and parameterised dynamic values
+        Given I set the "Content-Type" header to "application/json"
+        When I POST "/datasets"
+        """
+        {"title": "CPIH"}
+        """
+        Then I should receive the following JSON response with status "201":
+        """
+        {"id": "{{DYNAMIC_DATASET_ID}}", "title": "{{DYNAMIC_REGEX:^CPI[H]?$}}"}
+        """
+        And the JSON path "title" should be "{{DYNAMIC_ONE_OF:CPI|CPIH|RPI}}"
+        When I GET "/dynamic/validation/object"
+        Then the JSON path "timestamp" should be "{{DYNAMIC_RECENT_TIMESTAMP:60s}}"
+
+    Scenario: Dynamic values that are not strings
+        When I GET "/dynamic/validation/object"
+        Then I should receive the following JSON response:
+        """
+        {
+          "timestamp": "{{DYNAMIC_NOT_NULL}}",
+          "id": "{{DYNAMIC_UUID}}",
+          "embedded": "{{DYNAMIC_ANY}}",
+          "items": "{{DYNAMIC_ARRAY_LEN:2}}",
+          "uri_path": "{{DYNAMIC_URI_PATH}}",
+          "url": "{{DYNAMIC_URL}}"
+        }
+        """
+        When I GET "/example1"
+        Then I should receive the following JSON response:
+        """
+        {"example_type": "{{DYNAMIC_INT:1..100}}"}
+        """
+        And the JSON path "example_type" should be "{{DYNAMIC_NUMBER}}"
+
+    Scenario: Responses match golden file snapshots
+        When I GET "/dynamic/validation/object"
+        Then the response should match the snapshot "dynamic-object.json"
+        Given I set the "Content-Type" header to "text/plain"
+        When I POST "/example2"
+        """
+        foo bar
+        """
+        Then the response should match the snapshot "example2.txt"
+
+    Scenario: Request bodies and query parameters from files and tables
+        Given I set the "Content-Type" header to "application/json"
+        And I POST "/datasets"
+        """
+        {"title": "CPIH"}
+        """
+        And I store the JSON path "id" as "datasetID"
+        When I POST "/datasets" with the body from file "features/fixtures/dataset.json"
+        Then the HTTP status code should be "201"
+        And the JSON path "title" should match the regex "^Revision of [0-9a-f-]{36} at \d{4}-\d{2}-\d{2}T"
+        And I store the JSON path "title" as "revisionTitle"
+        When I GET "/datasets" with the query parameters:
+            | name  | value             |
+            | title | {{revisionTitle}} |
+        Then the JSON path "count" should be "1"
+        And the JSON path "items[0].title" should be "{{revisionTitle}}"
+
+    Scenario: Form encoded request bodies from a table
+        When I POST "/feedback" with the form:
+            | name    | value          |
+            | name    | Jane           |
+            | message | Great & useful |
+        Then I should receive the following JSON response with status "201":
+        """
+        {"name": "Jane", "message": "Great & useful"}
+        """
+
+    Scenario: Multipart file uploads
+        When I POST "/upload" with the multipart form:
+            | name  | type      | value                               |
+            | title | field     | CPIH                                |
+            | file  | file      | features/fixtures/observations.json |
+            | chunk | generated | 64KB                                |
+        Then the HTTP status code should be "201"
+        And I should receive the following JSON response in any order:
+        """
+        {
+          "fields": {"title": "CPIH"},
+          "files": [
+            {"name": "file", "filename": "observations.json", "content_type": "application/json", "size": 38},
+            {"name": "chunk", "filename": "chunk.bin", "content_type": "application/octet-stream", "size": 65536}
+          ]
+        }
+        """
+
+    Scenario: Polling until an asynchronous job completes
+        When I POST "/jobs"
+        """
+        """
+        Then the HTTP status code should be "202"
+        And the JSON path "state" should be "in_progress"
+        And I store the JSON path "id" as "jobID"
+        When I GET "/jobs/{{jobID}}/result" until the HTTP status code is 200 within 5 seconds
+        Then I should receive the following JSON response:
+        """
+        {"job_id": "{{jobID}}"}
+        """
+        When I GET "/jobs/{{jobID}}" until the JSON path "state" is "completed" within 5 seconds
+        Then the HTTP status code should be "200"
+
+    Scenario: Concurrent updates of the same version of a dataset
+        Given I set the "Content-Type" header to "application/json"
+        When I POST "/datasets"
+        """
+        {"title": "CPIH"}
+        """
+        And I store the JSON path "id" as "datasetID"
+        And I store the response header "ETag" as "etag"
+        And I set the "If-Match" header to "{{etag}}"
+        And I send 20 concurrent PUT requests to "/datasets/{{datasetID}}" with body:
+        """
+        {"title": "CPIH revised"}
+        """
+        Then exactly 1 response has status 200 and 19 have status 409
+        When I use the first concurrent response with status 409
+        Then I should receive the following response:
+        """
+        dataset has been modified
+        """
+        When I use the first concurrent response with status 200
+        Then the JSON path "title" should be "CPIH revised"
+
+    Scenario: Session cookies are kept between requests
+        When I GET "/whoami"
+        Then the HTTP status code should be "401"
+        When I POST "/login"
+        """
+        """
+        Then the HTTP status code should be "204"
+        And the response cookie "lang" should be "en"
+        And the response cookie "access_token" should have the following attributes:
+            | attribute | value  |
+            | HttpOnly  | true   |
+            | Secure    | true   |
+            | SameSite  | Strict |
+            | Path      | /      |
+            | Expires   | 1h     |
+        And the response cookie "lang" should have the following attributes:
+            | attribute | value   |
+            | HttpOnly  | false   |
+            | Expires   | session |
+        When I GET "/whoami"
+        Then I should receive the following JSON response with status "200":
+        """
+        {"lang": "en"}
+        """
+        When I set the cookie "lang" to "cy"
+        And I GET "/whoami"
+        Then I should receive the following JSON response:
+        """
+        {"lang": "cy"}
+        """
+        When I clear the cookies
+        And I GET "/whoami"
+        Then the HTTP status code should be "401"
+
+    Scenario: Redirects that are not followed
+        When I GET "/v1/datasets"
+        Then the HTTP status code should be "301"
+        And I should be redirected to "/v2/datasets" with status 301
+
+    Scenario: Redirects that are followed
+        Given redirects are followed
+        When I GET "/v1/datasets"
+        Then the HTTP status code should be "200"
+        And I should be redirected to "/datasets" with status 308
+        And the redirect chain should be:
+            | status | location     |
+            | 301    | /v2/datasets |
+            | 308    | /datasets    |
+
+    Scenario: Server-Sent Events are received as they are streamed
+        When I GET "/exports/progress" as a stream
+        Then the HTTP status code should be "200"
+        And the response header "Content-Type" should be "text/event-stream"
+        And I should receive the following events within 1 second:
+            | id | event    | data             |
+            | 1  | progress | {"percent": 0}   |
+            | 2  | progress | {"percent": 50}  |
+        And I should receive the following events within 1 second:
+            | event    | data             |
+            | progress | {"percent": 100} |
+            | done     | {}               |
+        And the stream should complete within 1 second
+        And the stream should have been flushed before it completed
+
+    Scenario: Newline delimited JSON is received as it is streamed
+        When I GET "/observations" as a stream
+        Then I should receive the following lines within 1 second:
+        """
+        {"time":"2024-01","value":100.2}
+        {"time":"2024-02","value":101.5}
+        """
+        And I should receive the following lines within 1 second:
+        """
+        {"time":"2024-03","value":99.8}
+        """
+        And the stream should have been flushed before it completed
+
+    Scenario: Compressed responses are decoded before they are asserted on
+        When I GET "/codelists/geography"
+        Then the response should not be compressed
+        And the JSON path "count" should be "500"
+        Given I set the "Accept-Encoding" header to "gzip"
+        When I GET "/codelists/geography"
+        Then the response should be compressed with "gzip"
+        And the JSON path "items[0].code" should be "K0000"
+        And the compressed response body should be at most 8KB
+        And the uncompressed response body should be at most 32KB
+        Given I set the "Accept-Encoding" header to "br, gzip"
+        When I GET "/codelists/geography"
+        Then the response should be compressed with "br"
+        And the response header "Content-Encoding" should be "br"
+        And the JSON path "count" should be "500"
+        Given I set the "Accept-Encoding" header to "deflate"
+        When I GET "/codelists/geography"
+        Then the response should be compressed with "deflate"
+        And the JSON path "items[499].label" should be "Geography 499"
+
+    Scenario: Responses compressed without being asked are still seen as compressed
+        When I GET "/codelists/geography/archive"
+        Then the response should be compressed with "gzip"
+        And the JSON path "count" should be "500"
+
+    Scenario: Responses are received within a latency budget
+        Given every response should be received within 2 seconds
+        When I GET "/example1"
+        Then the response should have been received within 500 milliseconds
+        Given redirects are followed
+        When I GET "/v1/datasets"
+        Then the HTTP status code should be "200"
+        And the response should have been received within 1 second
+
+    Scenario: The health endpoint reports an outage and the recovery from it
+        When I POST "/mongo/outage"
+            """
+            """
+        Then the HTTP status code should be "202"
+        And the "/health" endpoint should report "CRITICAL" for check "Mongo" within 3 seconds
+        And the "/health" endpoint should report "CRITICAL" within 1 second
+        And the HTTP status code should be "500"
+        And the "/health" endpoint should report "WARNING" within 5 seconds
+        And the "/health" endpoint should report "WARNING" for check "Mongo" within 1 second
+
+    Scenario: Topics are fetched from the topic API
+        Given the "topic-api" responds to GET "/topics/economy" with status 200 and body:
+            """
+            {"id": "economy", "title": "Economy", "state": "published"}
+            """
+        And the "topic-api" responds to GET "/topics/economy?lang=cy" with status 200 and body:
+            """
+            {"id": "economy", "title": "Economi"}
+            """
+        And the "topic-api" responds to GET "/topics/census" when the header "Authorization" is "Bearer publisher" with status 200 and body:
+            """
+            {"id": "census", "title": "Census"}
+            """
+        And the "topic-api" responds to GET "/topics/census" with status 404
+        When I GET "/topics/economy"
+        Then I should receive the following JSON response with status "200":
+            """
+            {"id": "economy", "title": "Economy"}
+            """
+        And the "topic-api" should have received 1 GET request to "/topics/economy"
+        When I GET "/topics/economy?lang=cy"
+        Then the JSON path "title" should be "Economi"
+        And the "topic-api" should have received 1 GET request to "/topics/economy?lang=cy"
+        And the "topic-api" should have received 2 GET requests to "/topics/economy"
+        When I GET "/topics/census"
+        Then the HTTP status code should be "404"
+        Given I set the "Authorization" header to "Bearer publisher"
+        When I GET "/topics/census"
+        Then the JSON path "title" should be "Census"
+        And the "topic-api" should have received 1 GET request to "/topics/census" with the header "Authorization" set to "Bearer publisher"
+        And the "topic-api" should not have received any PUT requests to "/topics/census"
+
+    Scenario: A topic is fetched once the topic API recovers
+        Given the "topic-api" responds to GET "/topics/economy" with the following responses in order:
+            | status | body                                  |
+            | 503    |                                       |
+            | 200    | {"id": "economy", "title": "Economy"} |
+        When I GET "/topics/economy"
+        Then the HTTP status code should be "200"
+        And the JSON path "title" should be "Economy"
+        And the service should have made 2 attempts to GET "/topics/economy" on the "topic-api"
+
+    Scenario: Fetching a topic is retried until the topic API has failed three times
+        Given the "topic-api" responds to GET "/topics/economy" with the statuses "503, 503, 503, 200"
+        When I GET "/topics/economy"
+        Then the HTTP status code should be "502"
+        And the service should have made 3 attempts to GET "/topics/economy" on the "topic-api"
+
+    Scenario: Fetching a topic is retried when the topic API is too slow or drops the connection
+        Given the "topic-api" responds to GET "/topics/economy" with the following responses in order:
+            | status | body                                  | delay | fault            |
+            | 200    | {"id": "economy", "title": "Slow"}    | 1s    |                  |
+            | 200    | {"id": "economy", "title": "Reset"}   |       | connection reset |
+            | 200    | {"id": "economy", "title": "Economy"} |       |                  |
+        When I GET "/topics/economy"
+        Then the JSON path "title" should be "Economy"
+        And the service should have made 3 attempts to GET "/topics/economy" on the "topic-api"
+        Given the "topic-api" responds to GET "/topics/census" with status 200 after 1 second
+        When I GET "/topics/census"
+        Then the HTTP status code should be "502"
+        And the service should have made 3 attempts to GET "/topics/census" on the "topic-api"
+        Given the "topic-api" closes the connection while responding to GET "/topics/housing"
+        When I GET "/topics/housing"
+        Then the HTTP status code should be "502"
+        And the service should have made 3 attempts to GET "/topics/housing" on the "topic-api"
+
+    Scenario: A malformed topic from the topic API is not retried
+        Given the "topic-api" responds to GET "/topics/economy" with status 200 and malformed JSON
+        When I GET "/topics/economy"
+        Then I should receive the following response:
+            """
+            topic API returned an invalid topic
+            """
+        And the HTTP status code should be "502"
+        And the service should have made 1 attempt to GET "/topics/economy" on the "topic-api"
+
+    Scenario: Renaming a topic updates it in the topic API
+        Given the "topic-api" responds to PUT "/topics/economy" with status 200
+        And I set the "Content-Type" header to "application/json"
+        When I PUT "/topics/economy"
+            """
+            {"title": "The economy"}
+            """
+        Then I should receive the following JSON response with status "200":
+            """
+            {"id": "economy", "title": "The economy"}
+            """
+        And the "topic-api" should have received 1 PUT request to "/topics/economy" with the header "Authorization" set to "Bearer example-service-token" and JSON body:
+            """
+            {"title": "The economy", "last_updated": "{{DYNAMIC_RECENT_TIMESTAMP:60s}}"}
+            """
+
+    Scenario: Fake API paths and bodies can use scenario variables
+        Given the "topic-api" responds to GET "/topics/economy" with status 200 and body:
+            """
+            {"id": "economy", "title": "Economy"}
+            """
+        And I GET "/topics/economy"
+        And I store the JSON path "id" as "topicID"
+        And the "topic-api" responds to PUT "/topics/{{topicID}}" with status 200
+        And I set the "Content-Type" header to "application/json"
+        When I PUT "/topics/{{topicID}}"
+            """
+            {"title": "The economy"}
+            """
+        Then the HTTP status code should be "200"
+        And the "topic-api" should have received 1 PUT request to "/topics/{{topicID}}" with JSON body:
+            """
+            {"title": "The economy", "last_updated": "{{DYNAMIC_RECENT_TIMESTAMP:60s}}"}
+            """
+
+    Scenario: A topic is not renamed in the topic API without a title
+        Given I set the "Content-Type" header to "application/json"
+        When I PUT "/topics/economy"
+            """
+            {"title": ""}
+            """
+        Then the HTTP status code should be "400"
+        And the "topic-api" should not have received any requests
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	componenttest "github.com/ONSdigital/dp-component-test"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/andybalholm/brotli"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
@@ -48,51 +57,99 @@ func ExampleHandler2(w http.ResponseWriter, _ *http.Request) {
 	fmt.Fprintf(w, "403 - Forbidden")
 }
 
-func ExampleHealthHandler(w http.ResponseWriter, _ *http.Request) {
-	var (
-		checkTime       = time.Now()
-		gitCommit       = "6584b786caac36b6214ffe04bf62f058d4021538"
-		language        = "go"
-		languageVersion = "go1.24.2"
-		msgHealthy      = "redis is healthy"
-		name            = "Redis"
-		statusCode      = 200
-		statusOK        = "OK"
-		version         = "v1.2.3"
-	)
-
-	healthVersion := healthcheck.VersionInfo{
-		BuildTime:       checkTime,
-		GitCommit:       gitCommit,
-		Version:         version,
-		Language:        language,
-		LanguageVersion: languageVersion,
-	}
-	healthCheck := componenttest.Check{
-		Name:        name,
-		Status:      statusOK,
-		StatusCode:  statusCode,
-		Message:     msgHealthy,
-		LastChecked: &checkTime,
-		LastSuccess: &checkTime,
-	}
-	responseBody := componenttest.HealthCheckTest{
-		Status:  statusOK,
-		Version: healthVersion,
-		Uptime:  time.Duration(4),
-		Checks:  []*componenttest.Check{&healthCheck},
-	}
-
-	healthResponse, err := json.Marshal(responseBody)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+// healthCheckTick is how often the service's health checks run, so an outage is only reported once a tick has passed
+const healthCheckTick = 500 * time.Millisecond
 
-	w.WriteHeader(http.StatusOK)
-	if _, writeErr := w.Write(healthResponse); writeErr != nil {
-		// optionally log the error or handle it
-		fmt.Printf("failed to write response: %v\n", writeErr)
+// outageDuration is how long an outage started by startOutageHandler lasts
+const outageDuration = 1500 * time.Millisecond
+
+// mongoOutage simulates an outage of the service's Mongo dependency
+type mongoOutage struct {
+	mu      sync.Mutex
+	started time.Time
+}
+
+// reported returns whether the outage has been picked up by a health check tick and has not yet ended
+func (o *mongoOutage) reported() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	elapsed := time.Since(o.started)
+	return !o.started.IsZero() && elapsed >= healthCheckTick && elapsed < healthCheckTick+outageDuration
+}
+
+func (o *mongoOutage) startOutageHandler(w http.ResponseWriter, _ *http.Request) {
+	o.mu.Lock()
+	o.started = time.Now()
+	o.mu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ExampleHealthHandler reports the health of the service, which is critical while a Mongo outage is reported
+func ExampleHealthHandler(outage *mongoOutage) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		var (
+			checkTime       = time.Now()
+			gitCommit       = "6584b786caac36b6214ffe04bf62f058d4021538"
+			language        = "go"
+			languageVersion = "go1.24.2"
+			msgHealthy      = "redis is healthy"
+			name            = "Redis"
+			statusCode      = 200
+			statusOK        = "OK"
+			statusWarning   = "WARNING"
+			statusCritical  = "CRITICAL"
+			version         = "v1.2.3"
+		)
+
+		healthVersion := healthcheck.VersionInfo{
+			BuildTime:       checkTime,
+			GitCommit:       gitCommit,
+			Version:         version,
+			Language:        language,
+			LanguageVersion: languageVersion,
+		}
+		healthCheck := componenttest.Check{
+			Name:        name,
+			Status:      statusOK,
+			StatusCode:  statusCode,
+			Message:     msgHealthy,
+			LastChecked: &checkTime,
+			LastSuccess: &checkTime,
+		}
+		mongoCheck := componenttest.Check{
+			Name:        "Mongo",
+			Status:      statusWarning,
+			StatusCode:  http.StatusInternalServerError,
+			Message:     fmt.Sprintf("mongo ping failed after %dms", time.Since(checkTime).Milliseconds()+1000),
+			LastChecked: &checkTime,
+			LastFailure: &checkTime,
+		}
+		status, responseStatus := statusWarning, http.StatusOK
+		if outage.reported() {
+			mongoCheck.Status = statusCritical
+			mongoCheck.Message = "mongo ping failed: connection refused"
+			status, responseStatus = statusCritical, http.StatusInternalServerError
+		}
+		responseBody := componenttest.HealthCheckTest{
+			Status:  status,
+			Version: healthVersion,
+			Uptime:  time.Duration(4),
+			Checks:  []*componenttest.Check{&healthCheck, &mongoCheck},
+		}
+
+		healthResponse, err := json.Marshal(responseBody)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(responseStatus)
+		if _, writeErr := w.Write(healthResponse); writeErr != nil {
+			// optionally log the error or handle it
+			fmt.Printf("failed to write response: %v\n", writeErr)
+		}
 	}
 }
 
@@ -173,21 +230,625 @@ func dynamicValidationArrayHandler(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
-func newRouter() http.Handler {
+type Dataset struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type datasetStore struct {
+	mu       sync.Mutex
+	datasets map[string]Dataset
+	// versions is incremented on every update of a dataset and is returned as its ETag
+	versions map[string]int
+}
+
+func datasetETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+func (s *datasetStore) createDatasetHandler(w http.ResponseWriter, r *http.Request) {
+	var dataset Dataset
+	if err := json.NewDecoder(r.Body).Decode(&dataset); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	dataset.ID = uuid.New().String()
+
+	s.mu.Lock()
+	s.datasets[dataset.ID] = dataset
+	s.versions[dataset.ID] = 1
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/datasets/"+dataset.ID)
+	w.Header().Set("ETag", datasetETag(1))
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(dataset); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// listDatasetsHandler returns the datasets in no particular order, optionally filtered by title
+func (s *datasetStore) listDatasetsHandler(w http.ResponseWriter, r *http.Request) {
+	title := r.URL.Query().Get("title")
+
+	s.mu.Lock()
+	items := make([]Dataset, 0, len(s.datasets))
+	for _, dataset := range s.datasets {
+		if title == "" || dataset.Title == title {
+			items = append(items, dataset)
+		}
+	}
+	s.mu.Unlock()
+
+	response := struct {
+		Count int       `json:"count"`
+		Items []Dataset `json:"items"`
+	}{
+		Count: len(items),
+		Items: items,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+func (s *datasetStore) getDatasetHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	s.mu.Lock()
+	dataset, ok := s.datasets[id]
+	version := s.versions[id]
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, "dataset not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", datasetETag(version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(dataset); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// updateDatasetHandler replaces the title of a dataset if the If-Match header matches its current ETag, so that
+// only one of several concurrent updates based on the same version succeeds
+func (s *datasetStore) updateDatasetHandler(w http.ResponseWriter, r *http.Request) {
+	var update Dataset
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	s.mu.Lock()
+	dataset, ok := s.datasets[id]
+	version := s.versions[id]
+	matched := r.Header.Get("If-Match") == datasetETag(version)
+	if ok && matched {
+		dataset.Title = update.Title
+		version++
+		s.datasets[id] = dataset
+		s.versions[id] = version
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, "dataset not found", http.StatusNotFound)
+		return
+	}
+	if !matched {
+		http.Error(w, "dataset has been modified", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", datasetETag(version))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(dataset); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// feedbackHandler accepts a form-encoded feedback submission and returns it as JSON
+func feedbackHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	feedback := struct {
+		Name    string `json:"name"`
+		Message string `json:"message"`
+	}{
+		Name:    r.PostForm.Get("name"),
+		Message: r.PostForm.Get("message"),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(feedback); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// uploadHandler accepts a multipart upload and describes the fields and files it contained
+func uploadHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	type uploadedFile struct {
+		Name        string `json:"name"`
+		Filename    string `json:"filename"`
+		ContentType string `json:"content_type"`
+		Size        int64  `json:"size"`
+	}
+
+	response := struct {
+		Fields map[string]string `json:"fields"`
+		Files  []uploadedFile    `json:"files"`
+	}{
+		Fields: make(map[string]string),
+		Files:  []uploadedFile{},
+	}
+
+	for name, values := range r.MultipartForm.Value {
+		response.Fields[name] = values[0]
+	}
+	for name, headers := range r.MultipartForm.File {
+		for _, header := range headers {
+			response.Files = append(response.Files, uploadedFile{
+				Name:        name,
+				Filename:    header.Filename,
+				ContentType: header.Header.Get("Content-Type"),
+				Size:        header.Size,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// jobDuration is how long a job takes to complete after it is created
+const jobDuration = 500 * time.Millisecond
+
+type Job struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+}
+
+type jobStore struct {
+	mu      sync.Mutex
+	created map[string]time.Time
+}
+
+// job returns the job with the id and whether it exists. Jobs complete once jobDuration has passed.
+func (s *jobStore) job(id string) (Job, bool) {
+	s.mu.Lock()
+	created, ok := s.created[id]
+	s.mu.Unlock()
+
+	state := "in_progress"
+	if time.Since(created) >= jobDuration {
+		state = "completed"
+	}
+	return Job{ID: id, State: state}, ok
+}
+
+func (s *jobStore) createJobHandler(w http.ResponseWriter, _ *http.Request) {
+	id := uuid.New().String()
+
+	s.mu.Lock()
+	s.created[id] = time.Now()
+	s.mu.Unlock()
+
+	job, _ := s.job(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+func (s *jobStore) getJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.job(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// getJobResultHandler returns 404 until the job has completed
+func (s *jobStore) getJobResultHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.job(mux.Vars(r)["id"])
+	if !ok || job.State != "completed" {
+		http.Error(w, "job result not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]string{"job_id": job.ID}); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// sessionDuration is how long the access token cookie set by loginHandler lasts
+const sessionDuration = time.Hour
+
+// loginHandler starts a session by setting an access token cookie and a language cookie
+func loginHandler(w http.ResponseWriter, _ *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "access_token",
+		Value:    uuid.New().String(),
+		Path:     "/",
+		MaxAge:   int(sessionDuration.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:  "lang",
+		Value: "en",
+		Path:  "/",
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// whoamiHandler returns the language of the session, or 401 if there is no access token cookie
+func whoamiHandler(w http.ResponseWriter, r *http.Request) {
+	if _, err := r.Cookie("access_token"); err != nil {
+		http.Error(w, "not signed in", http.StatusUnauthorized)
+		return
+	}
+
+	lang := "en"
+	if cookie, err := r.Cookie("lang"); err == nil {
+		lang = cookie.Value
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]string{"lang": lang}); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// streamInterval is the time between the messages written by the streaming handlers
+const streamInterval = 100 * time.Millisecond
+
+// progressEventsHandler streams the progress of an export as Server-Sent Events, flushing each event as it is written
+func progressEventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for i, percent := range []int{0, 50, 100} {
+		if i > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(streamInterval):
+			}
+		}
+		fmt.Fprintf(w, "id: %d\nevent: progress\ndata: {\"percent\": %d}\n\n", i+1, percent)
+		flusher.Flush()
+	}
+	fmt.Fprint(w, "event: done\ndata: {}\n\n")
+	flusher.Flush()
+}
+
+// observationsHandler streams observations as newline delimited JSON, flushing each line as it is written
+func observationsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	for i, value := range []float64{100.2, 101.5, 99.8} {
+		if i > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(streamInterval):
+			}
+		}
+		if err := encoder.Encode(map[string]interface{}{"time": fmt.Sprintf("2024-%02d", i+1), "value": value}); err != nil {
+			log.Printf("failed to encode observation: %v", err)
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// compressed encodes the responses of next with the first of br, gzip or deflate that the request accepts, as a
+// compression middleware would
+func compressed(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var encoder io.WriteCloser
+		var encoding string
+		for _, accepted := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+			switch encoding = strings.TrimSpace(accepted); encoding {
+			case "br":
+				encoder = brotli.NewWriter(w)
+			case "gzip":
+				encoder = gzip.NewWriter(w)
+			case "deflate":
+				encoder = zlib.NewWriter(w)
+			}
+			if encoder != nil {
+				break
+			}
+		}
+		if encoder == nil {
+			next(w, r)
+			return
+		}
+		defer encoder.Close()
+
+		w.Header().Set("Content-Encoding", encoding)
+		w.Header().Add("Vary", "Accept-Encoding")
+		next(compressedResponseWriter{ResponseWriter: w, encoder: encoder}, r)
+	}
+}
+
+// gzipped encodes the responses of next with gzip whatever the request accepts, as a server of pre-compressed files
+// would
+func gzipped(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		encoder := gzip.NewWriter(w)
+		defer encoder.Close()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		next(compressedResponseWriter{ResponseWriter: w, encoder: encoder}, r)
+	}
+}
+
+type compressedResponseWriter struct {
+	http.ResponseWriter
+	encoder io.Writer
+}
+
+func (w compressedResponseWriter) Write(p []byte) (int, error) {
+	return w.encoder.Write(p)
+}
+
+// codelistHandler returns a codelist large enough to be worth compressing
+func codelistHandler(w http.ResponseWriter, _ *http.Request) {
+	type code struct {
+		Code  string `json:"code"`
+		Label string `json:"label"`
+	}
+
+	codes := make([]code, 500)
+	for i := range codes {
+		codes[i] = code{Code: fmt.Sprintf("K%04d", i), Label: fmt.Sprintf("Geography %d", i)}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"count": len(codes), "items": codes}); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// redirectHandler redirects to the location with the status code
+func redirectHandler(location string, status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, location, status)
+	}
+}
+
+// Topic is the summary of a topic, which is fetched from the topic API
+type Topic struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// topicAPI fetches and updates topics in the topic API that the service depends on
+type topicAPI struct {
+	url    string
+	client *http.Client
+}
+
+// serviceAuthToken is the token the service authenticates itself to the topic API with
+const serviceAuthToken = "example-service-token"
+
+// topicUpdate is the update sent to the topic API when a topic is renamed
+type topicUpdate struct {
+	Title       string    `json:"title"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// topicAPIAttempts is how many times a topic is requested from the topic API before giving up
+const topicAPIAttempts = 3
+
+// topicAPIRetryInterval is how long to wait after a failed request to the topic API before retrying
+const topicAPIRetryInterval = 50 * time.Millisecond
+
+// getTopicHandler returns the topic from the topic API, in the language given by the lang query parameter. The
+// Authorization header of the request is forwarded to the topic API.
+func (t *topicAPI) getTopicHandler(w http.ResponseWriter, r *http.Request) {
+	topicURL := t.url + "/topics/" + mux.Vars(r)["id"]
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		topicURL += "?lang=" + lang
+	}
+
+	status, body, err := t.get(r, topicURL)
+	if err != nil {
+		http.Error(w, "topic API is unavailable", http.StatusBadGateway)
+		return
+	}
+
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		http.Error(w, "topic not found", http.StatusNotFound)
+		return
+	default:
+		http.Error(w, fmt.Sprintf("topic API responded with status %d", status), http.StatusBadGateway)
+		return
+	}
+
+	var topic Topic
+	if err := json.Unmarshal(body, &topic); err != nil {
+		http.Error(w, "topic API returned an invalid topic", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(topic); err != nil {
+		fmt.Printf("failed to write response: %v\n", err)
+	}
+}
+
+// get requests the URL from the topic API, forwarding the Authorization header of r. Requests that fail, time out or
+// are answered with a 5xx status are retried, up to topicAPIAttempts in total.
+func (t *topicAPI) get(r *http.Request, topicURL string) (status int, body []byte, err error) {
+	for attempt := 1; attempt <= topicAPIAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(topicAPIRetryInterval)
+		}
+
+		var req *http.Request
+		req, err = http.NewRequestWithContext(r.Context(), http.MethodGet, topicURL, http.NoBody)
+		if err != nil {
+			return 0, nil, err
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		var resp *http.Response
+		if resp, err = t.client.Do(req); err != nil {
+			continue
+		}
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+
+		if status = resp.StatusCode; status < http.StatusInternalServerError {
+			return status, body, nil
+		}
+	}
+	return status, body, err
+}
+
+// renameTopicHandler updates the title of the topic in the topic API, authenticating as the service
+func (t *topicAPI) renameTopicHandler(w http.ResponseWriter, r *http.Request) {
+	var topic Topic
+	if err := json.NewDecoder(r.Body).Decode(&topic); err != nil || topic.Title == "" {
+		http.Error(w, "a title is required", http.StatusBadRequest)
+		return
+	}
+	topic.ID = mux.Vars(r)["id"]
+
+	update, err := json.Marshal(topicUpdate{Title: topic.Title, LastUpdated: time.Now().UTC()})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPut, t.url+"/topics/"+topic.ID, bytes.NewReader(update))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+serviceAuthToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		http.Error(w, "topic API is unavailable", http.StatusBadGateway)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		http.Error(w, fmt.Sprintf("topic API responded with status %d", resp.StatusCode), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(topic); err != nil {
+		fmt.Printf("failed to write response: %v\n", err)
+	}
+}
+
+func newRouter(topicAPIURL string) http.Handler {
+	datasets := &datasetStore{datasets: make(map[string]Dataset), versions: make(map[string]int)}
+	jobs := &jobStore{created: make(map[string]time.Time)}
+	outage := &mongoOutage{}
+	topics := &topicAPI{url: topicAPIURL, client: &http.Client{Timeout: 500 * time.Millisecond}}
+
 	router := mux.NewRouter().StrictSlash(true)
 
 	router.HandleFunc("/example1", ExampleHandler1).Methods("GET")
 	router.HandleFunc("/example2", ExampleHandler2).Methods("POST")
-	router.HandleFunc("/health", ExampleHealthHandler).Methods("GET")
+	router.HandleFunc("/health", ExampleHealthHandler(outage)).Methods("GET")
+	router.HandleFunc("/mongo/outage", outage.startOutageHandler).Methods("POST")
 	router.HandleFunc("/dynamic/validation/object", dynamicValidationObjectHandler).Methods("GET")
 	router.HandleFunc("/dynamic/validation/array", dynamicValidationArrayHandler).Methods("GET")
+	router.HandleFunc("/datasets", datasets.createDatasetHandler).Methods("POST")
+	router.HandleFunc("/datasets", datasets.listDatasetsHandler).Methods("GET")
+	router.HandleFunc("/v1/datasets", redirectHandler("/v2/datasets", http.StatusMovedPermanently)).Methods("GET")
+	router.HandleFunc("/v2/datasets", redirectHandler("/datasets", http.StatusPermanentRedirect)).Methods("GET")
+	router.HandleFunc("/datasets/{id}", datasets.getDatasetHandler).Methods("GET")
+	router.HandleFunc("/datasets/{id}", datasets.updateDatasetHandler).Methods("PUT")
+	router.HandleFunc("/feedback", feedbackHandler).Methods("POST")
+	router.HandleFunc("/upload", uploadHandler).Methods("POST")
+	router.HandleFunc("/exports/progress", progressEventsHandler).Methods("GET")
+	router.HandleFunc("/observations", observationsHandler).Methods("GET")
+	router.HandleFunc("/codelists/geography", compressed(codelistHandler)).Methods("GET")
+	router.HandleFunc("/codelists/geography/archive", gzipped(codelistHandler)).Methods("GET")
+	router.HandleFunc("/login", loginHandler).Methods("POST")
+	router.HandleFunc("/whoami", whoamiHandler).Methods("GET")
+	router.HandleFunc("/jobs", jobs.createJobHandler).Methods("POST")
+	router.HandleFunc("/jobs/{id}", jobs.getJobHandler).Methods("GET")
+	router.HandleFunc("/jobs/{id}/result", jobs.getJobResultHandler).Methods("GET")
+	router.HandleFunc("/topics/{id}", topics.getTopicHandler).Methods("GET")
+	router.HandleFunc("/topics/{id}", topics.renameTopicHandler).Methods("PUT")
 
 	return router
 }
 
-func NewServer() *http.Server {
+// NewServer returns the service, which calls the topic API at topicAPIURL
+func NewServer(topicAPIURL string) *http.Server {
 	return &http.Server{
-		Handler:     newRouter(),
+		Handler:     newRouter(topicAPIURL),
 		ReadTimeout: 10 * time.Second,
 	}
 }
@@ -195,7 +856,7 @@ func NewServer() *http.Server {
 func main() {
 	server := &http.Server{
 		Addr:              ":10000",
-		Handler:           NewServer().Handler,
+		Handler:           NewServer(os.Getenv("TOPIC_API_URL")).Handler,
 		ReadTimeout:       10 * time.Second,
 		WriteTimeout:      10 * time.Second,
 		IdleTimeout:       60 * time.Second,
@@ -3,17 +3,23 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	componenttest "github.com/ONSdigital/dp-component-test"
+	"github.com/ONSdigital/dp-component-test/validator"
 	"github.com/cucumber/godog"
 	"github.com/cucumber/godog/colors"
 )
 
 var componentFlag = flag.Bool("component", false, "perform component tests")
 
+var updateSnapshotsFlag = flag.Bool("update-snapshots", false, "rewrite response snapshots from the actual responses")
+
 func (m *MyAppComponent) initialiser(h http.Handler) componenttest.ServiceInitialiser {
 	return func() (http.Handler, error) {
 		m.Handler = h
@@ -21,18 +27,94 @@ func (m *MyAppComponent) initialiser(h http.Handler) componenttest.ServiceInitia
 	}
 }
 
+// datasetIDValidator validates "{{DYNAMIC_DATASET_ID}}", the ID given to a new dataset
+var datasetIDValidator = componenttest.DynamicValidator{
+	ValidationFunc: validator.ValidateUUID,
+}
+
+// snapshots holds the golden files that responses are compared against
+var snapshots *componenttest.SnapshotStore
+
+// latencies reports the requests that took longer than 200ms
+var latencies *componenttest.LatencyRecorder
+
+// contract is loaded from swagger.yaml
+var contract *componenttest.OpenAPIContract
+
+// InitializeTestSuite creates the snapshots, latencies and contract afresh for each suite, so that each suite's
+// reports only cover its own scenarios
+func InitializeTestSuite(ctx *godog.TestSuiteContext) {
+	ctx.BeforeSuite(func() {
+		snapshots = componenttest.NewSnapshotStore("features/snapshots")
+		snapshots.Update = *updateSnapshotsFlag
+		latencies = componenttest.NewLatencyRecorder(200 * time.Millisecond)
+
+		var err error
+		contract, err = componenttest.NewOpenAPIContract("swagger.yaml")
+		if err != nil {
+			panic(err)
+		}
+	})
+	ctx.AfterSuite(func() {
+		fmt.Print(contract.Report())
+		fmt.Print(snapshots.Report())
+		fmt.Print(latencies.Report())
+	})
+}
+
 func InitializeScenario(godogCtx *godog.ScenarioContext) {
-	server := NewServer()
+	fakeAPIs := componenttest.NewFakeAPIFeature("topic-api")
+	server := NewServer(fakeAPIs.URL("topic-api"))
 	component := NewMyAppComponent(server.Handler)
 
 	apiFeature := componenttest.NewAPIFeature(component.initialiser(server.Handler))
+	apiFeature.OpenAPIContract = contract
+	apiFeature.Snapshots = snapshots
+	apiFeature.Latencies = latencies
+	apiFeature.PersistCookies = true
+	fakeAPIs.Variables = apiFeature.Variables
+	if err := apiFeature.RegisterDynamicValidator("DATASET_ID", datasetIDValidator); err != nil {
+		panic(err)
+	}
 
 	godogCtx.Before(func(ctx context.Context, _ *godog.Scenario) (context.Context, error) {
 		apiFeature.Reset()
+		fakeAPIs.Reset()
+		return ctx, nil
+	})
+
+	godogCtx.After(func(ctx context.Context, _ *godog.Scenario, _ error) (context.Context, error) {
+		fakeAPIs.Close()
 		return ctx, nil
 	})
 
 	apiFeature.RegisterSteps(godogCtx)
+	fakeAPIs.RegisterSteps(godogCtx)
+}
+
+// InitializeScenarioWithBaseURL runs the same scenarios over a real HTTP connection to the server listening at baseURL,
+// which calls the fake APIs
+func InitializeScenarioWithBaseURL(baseURL string, fakeAPIs *componenttest.FakeAPIFeature) func(*godog.ScenarioContext) {
+	return func(godogCtx *godog.ScenarioContext) {
+		apiFeature := componenttest.NewAPIFeatureWithBaseURL(baseURL, nil)
+		apiFeature.OpenAPIContract = contract
+		apiFeature.Snapshots = snapshots
+		apiFeature.Latencies = latencies
+		apiFeature.PersistCookies = true
+		fakeAPIs.Variables = apiFeature.Variables
+		if err := apiFeature.RegisterDynamicValidator("DATASET_ID", datasetIDValidator); err != nil {
+			panic(err)
+		}
+
+		godogCtx.Before(func(ctx context.Context, _ *godog.Scenario) (context.Context, error) {
+			apiFeature.Reset()
+			fakeAPIs.Reset()
+			return ctx, nil
+		})
+
+		apiFeature.RegisterSteps(godogCtx)
+		fakeAPIs.RegisterSteps(godogCtx)
+	}
 }
 
 func TestComponent(t *testing.T) {
@@ -44,9 +126,39 @@ func TestComponent(t *testing.T) {
 		}
 
 		status := godog.TestSuite{
-			Name:                "component_tests",
-			ScenarioInitializer: InitializeScenario,
-			Options:             &opts,
+			Name:                 "component_tests",
+			ScenarioInitializer:  InitializeScenario,
+			TestSuiteInitializer: InitializeTestSuite,
+			Options:              &opts,
+		}.Run()
+
+		if status > 0 {
+			t.Fail()
+		}
+	} else {
+		t.Skip()
+	}
+}
+
+func TestComponentWithBaseURL(t *testing.T) {
+	if *componentFlag {
+		fakeAPIs := componenttest.NewFakeAPIFeature("topic-api")
+		defer fakeAPIs.Close()
+
+		server := httptest.NewServer(NewServer(fakeAPIs.URL("topic-api")).Handler)
+		defer server.Close()
+
+		var opts = godog.Options{
+			Output: colors.Colored(os.Stdout),
+			Paths:  flag.Args(),
+			Format: "pretty",
+		}
+
+		status := godog.TestSuite{
+			Name:                 "component_tests_with_base_url",
+			ScenarioInitializer:  InitializeScenarioWithBaseURL(server.URL, fakeAPIs),
+			TestSuiteInitializer: InitializeTestSuite,
+			Options:              &opts,
 		}.Run()
 
 		if status > 0 {
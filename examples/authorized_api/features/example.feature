@@ -20,6 +20,7 @@ Feature: Example feature
             """
             401 - Unauthorized
             """
+        And the "zebedee" should not have received any requests
 
     Scenario: accessing restricted endpoint with authorization
         Given I am authorised
@@ -45,6 +46,7 @@ Feature: Example feature
             """
             accepted
             """
+        And the "zebedee" should have received 1 GET request to "/identity"
 
     Scenario: accessing restricted endpoint that requires identity without identity
         Given I am authorised
@@ -15,12 +15,15 @@ var componentFlag = flag.Bool("component", false, "perform component tests")
 
 func InitializeScenario(godogCtx *godog.ScenarioContext) {
 	authorizationFeature := componenttest.NewAuthorizationFeature()
+	fakeAPIs := componenttest.NewFakeAPIFeature()
+	fakeAPIs.AddHTTPFake("zebedee", authorizationFeature.FakeAuthService)
 	myAppFeature := NewMyAppComponent(authorizationFeature.FakeAuthService.ResolveURL(""))
 	apiFeature := componenttest.NewAPIFeatureWithHandler(myAppFeature.Handler)
 
 	godogCtx.Before(func(ctx context.Context, _ *godog.Scenario) (context.Context, error) {
 		apiFeature.Reset()
 		authorizationFeature.Reset()
+		fakeAPIs.Reset()
 		return ctx, nil
 	})
 
@@ -31,6 +34,7 @@ func InitializeScenario(godogCtx *godog.ScenarioContext) {
 
 	apiFeature.RegisterSteps(godogCtx)
 	authorizationFeature.RegisterSteps(godogCtx)
+	fakeAPIs.RegisterSteps(godogCtx)
 }
 
 func TestComponent(t *testing.T) {
@@ -0,0 +1,87 @@
+package componenttest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// faultConnectionReset closes the connection after sending the headers and part of the body
+	faultConnectionReset = "connection reset"
+	// faultMalformedJSON sends a body that is declared as JSON but cannot be parsed
+	faultMalformedJSON = "malformed JSON"
+)
+
+// faults are the faults that a stubbed response can have
+var faults = []string{faultConnectionReset, faultMalformedJSON}
+
+// malformedJSON is the body of a response with faultMalformedJSON, which is cut off part way through an object
+const malformedJSON = `{"id": "malformed", "items": [{"id": `
+
+// TheFakeAPIRespondsWithTheStatuses stubs a sequence of empty responses with the statuses, e.g. "503, 503, 200". The
+// last status is repeated once the sequence is exhausted.
+func (f *FakeAPIFeature) TheFakeAPIRespondsWithTheStatuses(name, method, path, header, value, statuses string) error {
+	var responses []stubResponse
+	for _, s := range strings.Split(statuses, ",") {
+		status, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("invalid status %q: %w", s, err)
+		}
+		responses = append(responses, stubResponse{status: status})
+	}
+
+	return f.stub(name, method, path, header, value, responses...)
+}
+
+// TheFakeAPIRespondsWithStatusAfter stubs an empty response with the status that is only sent once the delay has
+// passed, so that a service's timeouts can be tested
+func (f *FakeAPIFeature) TheFakeAPIRespondsWithStatusAfter(name, method, path, header, value string, status, amount int, unit string) error {
+	return f.stub(name, method, path, header, value, stubResponse{status: status, delay: latencyDuration(amount, unit)})
+}
+
+// TheFakeAPIRespondsWithStatusAndMalformedJSON stubs a response with the status and a body that is declared as JSON
+// but cannot be parsed
+func (f *FakeAPIFeature) TheFakeAPIRespondsWithStatusAndMalformedJSON(name, method, path, header, value string, status int) error {
+	return f.stub(name, method, path, header, value, stubResponse{status: status, fault: faultMalformedJSON})
+}
+
+// TheFakeAPIClosesTheConnectionWhileRespondingTo stubs a response that is cut off by the connection being closed
+// after the headers have been sent
+func (f *FakeAPIFeature) TheFakeAPIClosesTheConnectionWhileRespondingTo(name, method, path, header, value string) error {
+	return f.stub(name, method, path, header, value, stubResponse{status: http.StatusOK, fault: faultConnectionReset})
+}
+
+// TheServiceShouldHaveMadeAttemptsTo asserts that the fake API with the name received the expected number of
+// requests to the method and path, e.g. to check that a service retried a failed request
+func (f *FakeAPIFeature) TheServiceShouldHaveMadeAttemptsTo(expectedAttempts int, method, path, name string) error {
+	return f.assertRequestsReceived(name, expectedAttempts, method, path, "", "", nil)
+}
+
+// resetConnection sends the status and headers of the response and the first half of its body, declaring the length
+// of the full body, and then closes the connection so that the client fails to read the rest of the body
+func resetConnection(w http.ResponseWriter, response stubResponse) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "the connection cannot be closed by the fake API", http.StatusInternalServerError)
+		return
+	}
+
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to take over the connection: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+
+	body := response.body
+	if body == "" {
+		body = `{"id": "reset"}`
+	}
+
+	fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s",
+		response.status, http.StatusText(response.status), len(body), body[:len(body)/2])
+	// the connection is closed straight after, so a failed write leaves the client with the same broken response
+	_ = buf.Flush()
+}
@@ -0,0 +1,438 @@
+package componenttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cucumber/godog"
+	"github.com/maxcnunes/httpfake"
+)
+
+// FakeAPIFeature fakes the HTTP APIs that a service calls, such as the dataset API or the topic API. Each fake is
+// registered by name and listens on its own URL, which should be given to the service in its config. Scenarios stub
+// the responses of a fake with steps such as:
+//
+//	Given the "dataset-api" responds to GET "/datasets/cpih" with status 200 and body:
+//
+// Every request received by a fake is recorded, so that scenarios can assert what the service sent to it.
+type FakeAPIFeature struct {
+	// Variables, when set, replaces "{{name}}" references in paths, header values and bodies with scenario variables
+	Variables         *ScenarioVariables
+	fakes             map[string]*FakeAPI
+	dynamicValidators dynamicValidatorRegistry
+}
+
+// FakeAPI is a named fake of an HTTP API
+type FakeAPI struct {
+	Name string
+	Fake *httpfake.HTTPFake
+	// Requests records every request received by the fake
+	Requests *RequestRecorder
+	// owned is whether the fake was started by the FakeAPIFeature, which must then close it
+	owned bool
+	mu    sync.Mutex
+	// stubs are the stubbed responses, in the order they were added
+	stubs []*stub
+}
+
+// requestMatcher matches requests with a method and path that have every one of its query values and headers
+type requestMatcher struct {
+	method  string
+	path    string
+	query   url.Values
+	headers http.Header
+}
+
+// stub is the response, or sequence of responses, given to the requests it matches
+type stub struct {
+	requestMatcher
+	responses []stubResponse
+	calls     int
+}
+
+// stubResponse is a response given by a fake API
+type stubResponse struct {
+	status int
+	body   string
+	// delay is how long the fake waits before responding
+	delay time.Duration
+	// fault, if not empty, is how the response is broken, e.g. faultConnectionReset
+	fault string
+}
+
+// NewFakeAPIFeature returns a FakeAPIFeature with a fake API for each of the names, e.g. "dataset-api"
+func NewFakeAPIFeature(names ...string) *FakeAPIFeature {
+	f := &FakeAPIFeature{
+		fakes: make(map[string]*FakeAPI),
+	}
+
+	for _, name := range names {
+		f.AddFakeAPI(name)
+	}
+
+	return f
+}
+
+// AddFakeAPI starts a fake API with the name, if there is not one already, and returns its URL
+func (f *FakeAPIFeature) AddFakeAPI(name string) string {
+	if fake, ok := f.fakes[name]; ok {
+		return fake.Fake.ResolveURL("")
+	}
+
+	fake := httpfake.New()
+	f.fakes[name] = newFakeAPI(name, fake, true)
+	return fake.ResolveURL("")
+}
+
+// AddHTTPFake adds an existing fake under the name, such as the FakeAuthService of an AuthorizationFeature, so that
+// the steps can stub its responses and assert on the requests it received, and returns its URL. A fake that is not
+// already recording its requests is restarted on a new URL to record them, see RecordRequests, so it should be added
+// before its URL is given to the service. Stubbed responses take precedence over the fake's own handlers for the same
+// method and path. The fake is not closed by Close.
+func (f *FakeAPIFeature) AddHTTPFake(name string, fake *httpfake.HTTPFake) string {
+	f.fakes[name] = newFakeAPI(name, fake, false)
+	return fake.ResolveURL("")
+}
+
+// RegisterDynamicValidator adds a validator that is only available to this FakeAPIFeature, so that
+// "{{DYNAMIC_<validationType>}}" can be used in expected request bodies. Validators are kept when the feature is Reset.
+func (f *FakeAPIFeature) RegisterDynamicValidator(validationType string, v DynamicValidator) error {
+	v, err := checkDynamicValidator(validationType, v)
+	if err != nil {
+		return err
+	}
+
+	if f.dynamicValidators == nil {
+		f.dynamicValidators = make(dynamicValidatorRegistry)
+	}
+	f.dynamicValidators[validationType] = v
+	return nil
+}
+
+// URL returns the URL of the fake API with the name, or an empty string if there is no such fake
+func (f *FakeAPIFeature) URL(name string) string {
+	fake, ok := f.fakes[name]
+	if !ok {
+		return ""
+	}
+	return fake.Fake.ResolveURL("")
+}
+
+// Reset removes the responses stubbed on every fake API and forgets the requests they received
+func (f *FakeAPIFeature) Reset() {
+	for _, fake := range f.fakes {
+		fake.reset()
+	}
+}
+
+// Close stops every fake API started by the FakeAPIFeature
+func (f *FakeAPIFeature) Close() {
+	for _, fake := range f.fakes {
+		if fake.owned {
+			fake.Fake.Close()
+		}
+	}
+}
+
+func (f *FakeAPIFeature) RegisterSteps(ctx *godog.ScenarioContext) {
+	ctx.Step(`^the "([^"]*)" responds to (GET|POST|PUT|PATCH|DELETE) "([^"]*)"(?: when the header "([^"]*)" is "([^"]*)")? with status (\d+)$`, f.TheFakeAPIRespondsWithStatus)
+	ctx.Step(`^the "([^"]*)" responds to (GET|POST|PUT|PATCH|DELETE) "([^"]*)"(?: when the header "([^"]*)" is "([^"]*)")? with status (\d+) and body:$`, f.TheFakeAPIRespondsWithStatusAndBody)
+	ctx.Step(`^the "([^"]*)" responds to (GET|POST|PUT|PATCH|DELETE) "([^"]*)"(?: when the header "([^"]*)" is "([^"]*)")? with the following responses in order:$`, f.TheFakeAPIRespondsWithTheFollowingResponsesInOrder)
+	ctx.Step(`^the "([^"]*)" responds to (GET|POST|PUT|PATCH|DELETE) "([^"]*)"(?: when the header "([^"]*)" is "([^"]*)")? with the statuses "(\d+(?:, ?\d+)*)"$`, f.TheFakeAPIRespondsWithTheStatuses)
+	ctx.Step(`^the "([^"]*)" responds to (GET|POST|PUT|PATCH|DELETE) "([^"]*)"(?: when the header "([^"]*)" is "([^"]*)")? with status (\d+) after (\d+) (milliseconds|seconds?)$`, f.TheFakeAPIRespondsWithStatusAfter)
+	ctx.Step(`^the "([^"]*)" responds to (GET|POST|PUT|PATCH|DELETE) "([^"]*)"(?: when the header "([^"]*)" is "([^"]*)")? with status (\d+) and malformed JSON$`, f.TheFakeAPIRespondsWithStatusAndMalformedJSON)
+	ctx.Step(`^the "([^"]*)" closes the connection while responding to (GET|POST|PUT|PATCH|DELETE) "([^"]*)"(?: when the header "([^"]*)" is "([^"]*)")?$`, f.TheFakeAPIClosesTheConnectionWhileRespondingTo)
+	ctx.Step(`^the "([^"]*)" should have received (\d+) (GET|POST|PUT|PATCH|DELETE) requests? to "([^"]*)"(?: with the header "([^"]*)" set to "([^"]*)")?$`, f.TheFakeAPIShouldHaveReceivedRequests)
+	ctx.Step(`^the "([^"]*)" should have received (\d+) (GET|POST|PUT|PATCH|DELETE) requests? to "([^"]*)"(?: with the header "([^"]*)" set to "([^"]*)" and| with) JSON body:$`, f.TheFakeAPIShouldHaveReceivedRequestsWithJSONBody)
+	ctx.Step(`^the "([^"]*)" should not have received any (GET|POST|PUT|PATCH|DELETE) requests to "([^"]*)"$`, f.TheFakeAPIShouldNotHaveReceivedAnyRequestsTo)
+	ctx.Step(`^the "([^"]*)" should not have received any requests$`, f.TheFakeAPIShouldNotHaveReceivedAnyRequests)
+	ctx.Step(`^the service should have made (\d+) attempts? to (GET|POST|PUT|PATCH|DELETE) "([^"]*)" on the "([^"]*)"$`, f.TheServiceShouldHaveMadeAttemptsTo)
+}
+
+// TheFakeAPIRespondsWithStatus stubs an empty response with the status. If header is not empty, only requests with
+// the header set to value are given the response.
+func (f *FakeAPIFeature) TheFakeAPIRespondsWithStatus(name, method, path, header, value string, status int) error {
+	return f.stub(name, method, path, header, value, stubResponse{status: status})
+}
+
+// TheFakeAPIRespondsWithStatusAndBody stubs a response with the status and body. If header is not empty, only
+// requests with the header set to value are given the response.
+func (f *FakeAPIFeature) TheFakeAPIRespondsWithStatusAndBody(name, method, path, header, value string, status int, body *godog.DocString) error {
+	return f.stub(name, method, path, header, value, stubResponse{status: status, body: body.Content})
+}
+
+// TheFakeAPIRespondsWithTheFollowingResponsesInOrder stubs a sequence of responses, one per row of a table with a
+// "status" column and optional "body", "delay" and "fault" columns. The delay is a duration such as "500ms", and the
+// fault is "connection reset" or "malformed JSON". Each request is given the next response in the sequence, and the
+// last response is repeated once the sequence is exhausted.
+func (f *FakeAPIFeature) TheFakeAPIRespondsWithTheFollowingResponsesInOrder(name, method, path, header, value string, table *godog.Table) error {
+	if len(table.Rows) < 2 {
+		return fmt.Errorf("expected a header row and at least one response")
+	}
+
+	statusColumn, bodyColumn, delayColumn, faultColumn := -1, -1, -1, -1
+	for i, cell := range table.Rows[0].Cells {
+		switch cell.Value {
+		case "status":
+			statusColumn = i
+		case "body":
+			bodyColumn = i
+		case "delay":
+			delayColumn = i
+		case "fault":
+			faultColumn = i
+		default:
+			return fmt.Errorf("unexpected column %q, expected status, body, delay and fault", cell.Value)
+		}
+	}
+	if statusColumn < 0 {
+		return fmt.Errorf("expected a status column")
+	}
+
+	var responses []stubResponse
+	for _, row := range table.Rows[1:] {
+		status, err := strconv.Atoi(row.Cells[statusColumn].Value)
+		if err != nil {
+			return fmt.Errorf("invalid status %q: %w", row.Cells[statusColumn].Value, err)
+		}
+
+		response := stubResponse{status: status}
+		if bodyColumn >= 0 {
+			response.body = row.Cells[bodyColumn].Value
+		}
+		if delayColumn >= 0 && row.Cells[delayColumn].Value != "" {
+			if response.delay, err = time.ParseDuration(row.Cells[delayColumn].Value); err != nil {
+				return fmt.Errorf("invalid delay %q: %w", row.Cells[delayColumn].Value, err)
+			}
+		}
+		if faultColumn >= 0 && row.Cells[faultColumn].Value != "" {
+			if response.fault = row.Cells[faultColumn].Value; !slices.Contains(faults, response.fault) {
+				return fmt.Errorf("unknown fault %q, expected one of: %s", response.fault, strings.Join(faults, ", "))
+			}
+		}
+		responses = append(responses, response)
+	}
+
+	return f.stub(name, method, path, header, value, responses...)
+}
+
+// stub adds the responses to the fake API with the name for requests to the method and path, which can include a
+// query that requests must match
+func (f *FakeAPIFeature) stub(name, method, path, header, value string, responses ...stubResponse) error {
+	fake, err := f.fake(name)
+	if err != nil {
+		return err
+	}
+
+	matcher, err := f.newRequestMatcher(method, path, header, value)
+	if err != nil {
+		return err
+	}
+
+	for i := range responses {
+		if responses[i].body, err = f.Variables.Interpolate(responses[i].body); err != nil {
+			return err
+		}
+	}
+
+	fake.add(&stub{requestMatcher: matcher, responses: responses})
+	return nil
+}
+
+// fake returns the fake API with the name
+func (f *FakeAPIFeature) fake(name string) (*FakeAPI, error) {
+	fake, ok := f.fakes[name]
+	if !ok {
+		return nil, fmt.Errorf("there is no fake API named %q, the fake APIs are: %s", name, strings.Join(f.names(), ", "))
+	}
+	return fake, nil
+}
+
+// names returns the names of the fake APIs in alphabetical order
+func (f *FakeAPIFeature) names() []string {
+	names := make([]string, 0, len(f.fakes))
+	for name := range f.fakes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newFakeAPI returns a FakeAPI for the fake that records its requests and gives the stubbed responses ahead of any
+// handlers of the fake. The stubs are kept by the FakeAPI rather than added to the fake's handlers, which httpfake
+// reads without a lock while serving requests.
+func newFakeAPI(name string, fake *httpfake.HTTPFake, owned bool) *FakeAPI {
+	a := &FakeAPI{
+		Name:     name,
+		Fake:     fake,
+		Requests: RecordRequests(fake),
+		owned:    owned,
+	}
+	a.Requests.wrap(a.handler)
+	return a
+}
+
+// handler returns a handler that responds to requests for the method and path of a stub, and passes any other
+// request on to next
+func (a *FakeAPI) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		stubbed := slices.ContainsFunc(a.stubs, func(s *stub) bool {
+			return s.method == r.Method && s.path == r.URL.Path
+		})
+		a.mu.Unlock()
+
+		if !stubbed {
+			next.ServeHTTP(w, r)
+			return
+		}
+		a.respond(w, r)
+	})
+}
+
+// add adds the stub
+func (a *FakeAPI) add(s *stub) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stubs = append(a.stubs, s)
+}
+
+// reset removes every stub and forgets the requests received
+func (a *FakeAPI) reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stubs = nil
+	a.Requests.Reset()
+}
+
+// respond writes the next response of the stub that matches r. When several stubs match, the one with the most query
+// and header conditions is used, and then the one added last.
+func (a *FakeAPI) respond(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	var match *stub
+	for _, s := range a.stubs {
+		if s.matches(r.Method, r.URL, r.Header) && (match == nil || s.conditions() >= match.conditions()) {
+			match = s
+		}
+	}
+
+	var response stubResponse
+	if match != nil {
+		response = match.responses[min(match.calls, len(match.responses)-1)]
+		match.calls++
+	}
+	a.mu.Unlock()
+
+	if match == nil {
+		http.Error(w, fmt.Sprintf("no response is stubbed on %q for %s %s", a.Name, r.Method, r.URL), http.StatusNotFound)
+		return
+	}
+
+	if response.delay > 0 {
+		select {
+		case <-time.After(response.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch response.fault {
+	case faultConnectionReset:
+		resetConnection(w, response)
+		return
+	case faultMalformedJSON:
+		w.Header().Set("Content-Type", "application/json")
+		response.body = malformedJSON
+	default:
+		if json.Valid([]byte(response.body)) {
+			w.Header().Set("Content-Type", "application/json")
+		}
+	}
+
+	w.WriteHeader(response.status)
+	// a failed write means the client has gone away, which the service under test reports itself
+	_, _ = w.Write([]byte(response.body))
+}
+
+// newRequestMatcher returns a requestMatcher for the method and path, which can include a query. If header is not
+// empty, requests must also have the header set to value. Scenario variables are replaced in the path and value.
+func (f *FakeAPIFeature) newRequestMatcher(method, path, header, value string) (requestMatcher, error) {
+	path, err := f.Variables.Interpolate(path)
+	if err != nil {
+		return requestMatcher{}, err
+	}
+	if value, err = f.Variables.Interpolate(value); err != nil {
+		return requestMatcher{}, err
+	}
+
+	target, err := url.Parse(path)
+	if err != nil {
+		return requestMatcher{}, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+
+	m := requestMatcher{
+		method:  method,
+		path:    target.Path,
+		query:   target.Query(),
+		headers: make(http.Header),
+	}
+	if header != "" {
+		m.headers.Set(header, value)
+	}
+	return m, nil
+}
+
+// matches returns whether a request with the method, URL and headers has the matcher's method and path, and every
+// query value and header of the matcher
+func (m requestMatcher) matches(method string, u *url.URL, header http.Header) bool {
+	if method != m.method || u.Path != m.path {
+		return false
+	}
+
+	query := u.Query()
+	for key, values := range m.query {
+		for _, value := range values {
+			if !slices.Contains(query[key], value) {
+				return false
+			}
+		}
+	}
+
+	for key := range m.headers {
+		if header.Get(key) != m.headers.Get(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// conditions returns the number of query values and headers a request must have to be matched
+func (m requestMatcher) conditions() int {
+	count := len(m.headers)
+	for _, values := range m.query {
+		count += len(values)
+	}
+	return count
+}
+
+// String describes the requests matched, e.g. "GET /datasets?limit=10 with the header "Authorization" set to "x""
+func (m requestMatcher) String() string {
+	description := m.method + " " + m.path
+	if len(m.query) > 0 {
+		description += "?" + m.query.Encode()
+	}
+	for key := range m.headers {
+		description += fmt.Sprintf(" with the header %q set to %q", key, m.headers.Get(key))
+	}
+	return description
+}
@@ -0,0 +1,111 @@
+package componenttest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cucumber/godog"
+)
+
+// TheFakeAPIShouldHaveReceivedRequests asserts that the fake API with the name received the expected number of
+// requests to the method and path, which can include a query. If header is not empty, only requests with the header
+// set to value are counted.
+func (f *FakeAPIFeature) TheFakeAPIShouldHaveReceivedRequests(name string, expectedCount int, method, path, header, value string) error {
+	return f.assertRequestsReceived(name, expectedCount, method, path, header, value, nil)
+}
+
+// TheFakeAPIShouldHaveReceivedRequestsWithJSONBody asserts that the fake API with the name received the expected
+// number of requests to the method and path with the JSON body, which can contain "{{DYNAMIC_*}}" values and scenario
+// variables. If header is not empty, only requests with the header set to value are counted.
+func (f *FakeAPIFeature) TheFakeAPIShouldHaveReceivedRequestsWithJSONBody(name string, expectedCount int, method, path, header, value string, body *godog.DocString) error {
+	expected, err := f.Variables.Interpolate(body.Content)
+	if err != nil {
+		return err
+	}
+
+	validators := defaultDynamicValidatorsWith(f.dynamicValidators)
+
+	return f.assertRequestsReceived(name, expectedCount, method, path, header, value, func(request RecordedRequest) error {
+		actualValidated, expectedValidated, err := validators.validateDynamicValues(string(request.Body), expected)
+		if err != nil {
+			return err
+		}
+		return assertJSONEqual("request body", expectedValidated, actualValidated)
+	})
+}
+
+// TheFakeAPIShouldNotHaveReceivedAnyRequestsTo asserts that the fake API with the name received no requests to the
+// method and path, which can include a query
+func (f *FakeAPIFeature) TheFakeAPIShouldNotHaveReceivedAnyRequestsTo(name, method, path string) error {
+	return f.assertRequestsReceived(name, 0, method, path, "", "", nil)
+}
+
+// TheFakeAPIShouldNotHaveReceivedAnyRequests asserts that the fake API with the name received no requests at all
+func (f *FakeAPIFeature) TheFakeAPIShouldNotHaveReceivedAnyRequests(name string) error {
+	fake, err := f.fake(name)
+	if err != nil {
+		return err
+	}
+
+	if requests := fake.Requests.Requests(); len(requests) > 0 {
+		return fmt.Errorf("expected %q not to have received any requests, got %d:%s", name, len(requests), describeRecordedRequests(requests, nil))
+	}
+	return nil
+}
+
+// assertRequestsReceived asserts that the fake API with the name received the expected number of requests that are
+// matched by the method, path, header and value, and for which check, if given, returns nil
+func (f *FakeAPIFeature) assertRequestsReceived(name string, expectedCount int, method, path, header, value string, check func(RecordedRequest) error) error {
+	fake, err := f.fake(name)
+	if err != nil {
+		return err
+	}
+
+	matcher, err := f.newRequestMatcher(method, path, header, value)
+	if err != nil {
+		return err
+	}
+
+	requests := fake.Requests.Requests()
+	failures := make(map[int]error)
+	count := 0
+	for i, request := range requests {
+		if !matcher.matches(request.Method, request.URL, request.Header) {
+			continue
+		}
+		if check != nil {
+			if err := check(request); err != nil {
+				failures[i] = err
+				continue
+			}
+		}
+		count++
+	}
+
+	if count != expectedCount {
+		expected := matcher.String()
+		if check != nil {
+			expected += " with the expected JSON body"
+		}
+		return fmt.Errorf("expected %q to have received %d request(s) to %s, got %d:%s", name, expectedCount, expected, count,
+			describeRecordedRequests(requests, failures))
+	}
+	return nil
+}
+
+// describeRecordedRequests lists the requests, each on its own line, with the reason the request at that index did
+// not match if there is one in failures
+func describeRecordedRequests(requests []RecordedRequest, failures map[int]error) string {
+	if len(requests) == 0 {
+		return "\n  no requests were received"
+	}
+
+	var sb strings.Builder
+	for i, request := range requests {
+		fmt.Fprintf(&sb, "\n  %s", request)
+		if err, ok := failures[i]; ok {
+			fmt.Fprintf(&sb, ": %s", strings.ReplaceAll(err.Error(), "\n", "\n    "))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,59 @@
+package componenttest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFakeAPIShouldHaveReceivedRequestsWithJSONBody(t *testing.T) {
+	Convey("Given a fake API that has received a PUT request", t, func() {
+		f := NewFakeAPIFeature("dataset-api")
+		defer f.Close()
+
+		So(f.TheFakeAPIRespondsWithStatus("dataset-api", "PUT", "/instances/123", "", "", http.StatusOK), ShouldBeNil)
+
+		body := `{"id": "9b4c3f6e-5d2a-4e8b-9c1f-2a3b4c5d6e7f", "state": "edition-confirmed"}`
+		req, err := http.NewRequest(http.MethodPut, f.URL("dataset-api")+"/instances/123", strings.NewReader(body))
+		So(err, ShouldBeNil)
+		resp, err := http.DefaultClient.Do(req)
+		So(err, ShouldBeNil)
+		resp.Body.Close()
+
+		Convey("When the request is asserted with the same body and a dynamic value", func() {
+			err := f.TheFakeAPIShouldHaveReceivedRequestsWithJSONBody("dataset-api", 1, "PUT", "/instances/123", "", "",
+				&godog.DocString{Content: `{"id": "{{DYNAMIC_UUID}}", "state": "edition-confirmed"}`})
+
+			Convey("Then there is no error", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When the request is asserted with scenario variables in the path and body", func() {
+			f.Variables = NewScenarioVariables()
+			f.Variables.Set("instanceID", "123")
+			f.Variables.Set("state", "edition-confirmed")
+
+			err := f.TheFakeAPIShouldHaveReceivedRequestsWithJSONBody("dataset-api", 1, "PUT", "/instances/{{instanceID}}", "", "",
+				&godog.DocString{Content: `{"id": "{{DYNAMIC_UUID}}", "state": "{{state}}"}`})
+
+			Convey("Then the variables are replaced and there is no error", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When the request is asserted with a body that has an extra field", func() {
+			err := f.TheFakeAPIShouldHaveReceivedRequestsWithJSONBody("dataset-api", 1, "PUT", "/instances/123", "", "",
+				&godog.DocString{Content: `{"id": "{{DYNAMIC_UUID}}", "state": "edition-confirmed", "version": 1}`})
+
+			Convey("Then the request is listed with the missing field", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "PUT /instances/123: request body does not match the expected JSON, 1 difference(s):")
+				So(err.Error(), ShouldContainSubstring, "missing field at version")
+			})
+		})
+	})
+}
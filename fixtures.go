@@ -0,0 +1,135 @@
+package componenttest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cucumber/godog"
+	"github.com/google/uuid"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// fixtureFuncs are the functions available to fixture templates
+var fixtureFuncs = template.FuncMap{
+	"now": func() string {
+		return time.Now().UTC().Format(time.RFC3339)
+	},
+	"uuid": uuid.NewString,
+}
+
+// IRequestWithTheBodyFromFile makes a request to the provided path with the current headers and the body read
+// from the fixture file, see loadFixture
+func (f *APIFeature) IRequestWithTheBodyFromFile(method, path, fixturePath string) error {
+	body, err := f.loadFixture(fixturePath)
+	if err != nil {
+		return err
+	}
+	return f.makeRequest(method, path, body)
+}
+
+/*
+IRequestWithTheForm makes a request to the provided path with the current headers and a form-encoded body built
+from the table. The Content-Type header is set to application/x-www-form-urlencoded unless a header has been set.
+
+Table should look like:
+| name  | value |
+| title | CPIH  |
+*/
+func (f *APIFeature) IRequestWithTheForm(method, path string, table *godog.Table) error {
+	values, err := f.tableValues(table)
+	if err != nil {
+		return err
+	}
+
+	var headers map[string]string
+	if !f.hasRequestHeader("Content-Type") {
+		headers = map[string]string{"Content-Type": formContentType}
+	}
+
+	return f.makeRequestWithHeaders(method, path, []byte(values.Encode()), headers)
+}
+
+/*
+IGetWithTheQueryParameters makes a GET request to the provided path with the current headers and the query
+parameters in the table added to any already in the path. A name may be repeated to give several values.
+
+Table should look like:
+| name  | value |
+| limit | 10    |
+*/
+func (f *APIFeature) IGetWithTheQueryParameters(path string, table *godog.Table) error {
+	values, err := f.tableValues(table)
+	if err != nil {
+		return err
+	}
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	return f.makeRequest("GET", path+separator+values.Encode(), nil)
+}
+
+// loadFixture reads a fixture file, relative to the directory the tests are run from, and expands it as a Go
+// template. Scenario variables are available as fields, e.g. "{{ .datasetID }}", along with the functions "now",
+// the current time in RFC3339 format, and "uuid", a new random UUID.
+func (f *APIFeature) loadFixture(fixturePath string) ([]byte, error) {
+	content, err := os.ReadFile(fixturePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %q: %w", fixturePath, err)
+	}
+
+	tmpl, err := template.New(fixturePath).Funcs(fixtureFuncs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %q: %w", fixturePath, err)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, f.Variables.Values()); err != nil {
+		return nil, fmt.Errorf("failed to expand fixture %q: %w", fixturePath, err)
+	}
+	return body.Bytes(), nil
+}
+
+// tableValues returns the name and value rows of a table, skipping its header row, with any scenario variable
+// references replaced
+func (f *APIFeature) tableValues(table *godog.Table) (url.Values, error) {
+	values := make(url.Values)
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue
+		}
+		if len(row.Cells) != 2 {
+			return nil, fmt.Errorf("table row %d has %d cells, expected a name and a value", i, len(row.Cells))
+		}
+
+		name, err := f.Variables.Interpolate(row.Cells[0].Value)
+		if err != nil {
+			return nil, err
+		}
+		value, err := f.Variables.Interpolate(row.Cells[1].Value)
+		if err != nil {
+			return nil, err
+		}
+		values.Add(name, value)
+	}
+	return values, nil
+}
+
+// hasRequestHeader reports whether a header has been set for the next request, ignoring case
+func (f *APIFeature) hasRequestHeader(header string) bool {
+	for key := range f.requestHeaders {
+		if http.CanonicalHeaderKey(key) == http.CanonicalHeaderKey(header) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package componenttest
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// writeFixture writes content to a fixture file in a temporary directory and returns its path
+func writeFixture(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFixture(t *testing.T) {
+	Convey("Given an APIFeature with a stored scenario variable", t, func() {
+		f := &APIFeature{Variables: NewScenarioVariables()}
+		f.Variables.Set("datasetID", "cpih")
+
+		Convey("When a fixture refers to the variable and the template functions", func() {
+			body, err := f.loadFixture(writeFixture(t, `{"id": "{{ .datasetID }}", "at": "{{ now }}", "ref": "{{ uuid }}"}`))
+
+			Convey("Then they are expanded", func() {
+				So(err, ShouldBeNil)
+				So(string(body), ShouldStartWith, `{"id": "cpih", "at": "`+time.Now().UTC().Format("2006-01-02"))
+				So(regexp.MustCompile(`"ref": "[0-9a-f-]{36}"`).Match(body), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a fixture uses template actions", func() {
+			body, err := f.loadFixture(writeFixture(t, `{{ if .datasetID }}{{ .datasetID | printf "%q" }}{{ end }}`))
+
+			Convey("Then they are run", func() {
+				So(err, ShouldBeNil)
+				So(string(body), ShouldEqual, `"cpih"`)
+			})
+		})
+
+		Convey("When a fixture refers to a variable that has not been stored", func() {
+			_, err := f.loadFixture(writeFixture(t, `{"id": "{{ .editionID }}"}`))
+
+			Convey("Then it fails to expand", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "failed to expand fixture")
+				So(err.Error(), ShouldContainSubstring, "editionID")
+			})
+		})
+
+		Convey("When a fixture is not a valid template", func() {
+			_, err := f.loadFixture(writeFixture(t, `{"id": "{{ .datasetID }"}`))
+
+			Convey("Then it fails to parse", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "failed to parse fixture")
+			})
+		})
+
+		Convey("When the fixture does not exist", func() {
+			_, err := f.loadFixture(filepath.Join(t.TempDir(), "missing.json"))
+
+			Convey("Then it fails to read", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "failed to read fixture")
+			})
+		})
+	})
+}
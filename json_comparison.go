@@ -0,0 +1,293 @@
+package componenttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cucumber/godog"
+)
+
+// jsonComparison describes how leniently an actual JSON document is compared to an expected one
+type jsonComparison struct {
+	// subset allows the actual document to contain fields and array elements that are not expected
+	subset bool
+	// anyOrder allows array elements to appear in any order
+	anyOrder bool
+	// validators validates any dynamic placeholders in the expected document
+	validators dynamicValidatorRegistry
+}
+
+// align returns actual reshaped towards expected so that the strict comparison made by validateDynamicValues
+// and assertJSONEqual applies the leniency of the comparison: in subset mode unexpected fields and array elements
+// are dropped, and in any order mode array elements are reordered to line up with the elements they match.
+// Anything that does not match is left in place so that it is reported by the strict comparison.
+func (c jsonComparison) align(actual, expected interface{}) interface{} {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return actual
+		}
+
+		aligned := make(map[string]interface{}, len(act))
+		for key, actValue := range act {
+			expValue, exists := exp[key]
+			switch {
+			case exists:
+				aligned[key] = c.align(actValue, expValue)
+			case !c.subset:
+				aligned[key] = actValue
+			}
+		}
+		return aligned
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return actual
+		}
+		return c.alignArray(act, exp)
+	default:
+		return actual
+	}
+}
+
+// alignArray pairs each expected element with a matching actual element and returns the actual elements in
+// the order of the expected elements they match
+func (c jsonComparison) alignArray(actual, expected []interface{}) []interface{} {
+	if !c.subset && !c.anyOrder {
+		aligned := make([]interface{}, len(actual))
+		for i := range actual {
+			if i < len(expected) {
+				aligned[i] = c.align(actual[i], expected[i])
+			} else {
+				aligned[i] = actual[i]
+			}
+		}
+		return aligned
+	}
+
+	pairs := c.matchArray(actual, expected)
+
+	used := make([]bool, len(actual))
+	for _, j := range pairs {
+		if j >= 0 {
+			used[j] = true
+		}
+	}
+
+	// in any order, pair each unmatched expected element with the next unused actual element, so that the strict
+	// comparison reports the differences between them rather than only a length mismatch. In subset mode the
+	// unused actual elements are not expected, so an unmatched expected element is left unpaired and reported
+	// as missing rather than compared with an arbitrary element.
+	if !c.subset {
+		next := 0
+		for i, j := range pairs {
+			if j >= 0 {
+				continue
+			}
+			for next < len(actual) && used[next] {
+				next++
+			}
+			if next >= len(actual) {
+				break
+			}
+			pairs[i] = next
+			used[next] = true
+		}
+	}
+
+	aligned := make([]interface{}, 0, len(actual))
+	for i, j := range pairs {
+		if j >= 0 {
+			aligned = append(aligned, c.align(actual[j], expected[i]))
+		}
+	}
+
+	if !c.subset {
+		for j := range actual {
+			if !used[j] {
+				aligned = append(aligned, actual[j])
+			}
+		}
+	}
+	return aligned
+}
+
+// matchArray returns, for each expected element, the index of the actual element it is paired with or -1.
+// In order, expected elements are matched as a subsequence of the actual elements. In any order, a maximum
+// matching is found so that as many expected elements as possible are paired with distinct actual elements.
+func (c jsonComparison) matchArray(actual, expected []interface{}) []int {
+	pairs := make([]int, len(expected))
+	for i := range pairs {
+		pairs[i] = -1
+	}
+
+	if !c.anyOrder {
+		start := 0
+		for i := range expected {
+			for j := start; j < len(actual); j++ {
+				if c.matches(actual[j], expected[i]) {
+					pairs[i] = j
+					start = j + 1
+					break
+				}
+			}
+		}
+		return pairs
+	}
+
+	matchesExpected := make([][]bool, len(expected))
+	for i := range expected {
+		matchesExpected[i] = make([]bool, len(actual))
+		for j := range actual {
+			matchesExpected[i][j] = c.matches(actual[j], expected[i])
+		}
+	}
+
+	pairedWith := make([]int, len(actual))
+	for j := range pairedWith {
+		pairedWith[j] = -1
+	}
+
+	// augmenting path search for a maximum bipartite matching
+	var augment func(i int, visited []bool) bool
+	augment = func(i int, visited []bool) bool {
+		for j := range actual {
+			if !matchesExpected[i][j] || visited[j] {
+				continue
+			}
+			visited[j] = true
+			if pairedWith[j] < 0 || augment(pairedWith[j], visited) {
+				pairedWith[j] = i
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range expected {
+		augment(i, make([]bool, len(actual)))
+	}
+
+	for j, i := range pairedWith {
+		if i >= 0 {
+			pairs[i] = j
+		}
+	}
+	return pairs
+}
+
+// matches reports whether actual matches expected under the comparison, validating any dynamic placeholders
+func (c jsonComparison) matches(actual, expected interface{}) bool {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok || (!c.subset && len(act) != len(exp)) {
+			return false
+		}
+		for key, expValue := range exp {
+			actValue, exists := act[key]
+			if !exists || !c.matches(actValue, expValue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok || (!c.subset && len(act) != len(exp)) {
+			return false
+		}
+		if !c.subset && !c.anyOrder {
+			for i := range exp {
+				if !c.matches(act[i], exp[i]) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, j := range c.matchArray(act, exp) {
+			if j < 0 {
+				return false
+			}
+		}
+		return true
+	case string:
+		if strings.HasPrefix(exp, "{{DYNAMIC_") {
+			return c.validators.validateDynamicValue(actual, exp, "") == nil
+		}
+		return actual == exp
+	default:
+		return reflect.DeepEqual(actual, expected)
+	}
+}
+
+// assertJSONResponse compares the response body to the expected JSON using the comparison, validating any
+// "{{DYNAMIC_*}}" placeholders
+func (f *APIFeature) assertJSONResponse(expectedContent string, comparison jsonComparison) error {
+	body, err := f.readResponseBody()
+	if err != nil {
+		return err
+	}
+
+	expected, err := f.Variables.Interpolate(expectedContent)
+	if err != nil {
+		return err
+	}
+
+	validators := f.dynamicValidatorRegistry()
+	comparison.validators = validators
+
+	actual := string(body)
+	if comparison.subset || comparison.anyOrder {
+		if actual, err = alignJSON(actual, expected, comparison); err != nil {
+			return err
+		}
+	}
+
+	actualValidated, expectedValidated, err := validators.validateDynamicValues(actual, expected)
+	if err != nil {
+		return err
+	}
+
+	return assertJSONEqual("response body", expectedValidated, actualValidated)
+}
+
+// alignJSON reshapes the actual JSON document towards the expected document, see jsonComparison.align
+func alignJSON(actual, expected string, comparison jsonComparison) (string, error) {
+	var actualJSON, expectedJSON interface{}
+
+	if err := json.Unmarshal([]byte(actual), &actualJSON); err != nil {
+		return "", fmt.Errorf("failed to unmarshal actual JSON: %w", err)
+	}
+	if err := json.Unmarshal([]byte(expected), &expectedJSON); err != nil {
+		return "", fmt.Errorf("failed to unmarshal expected JSON: %w", err)
+	}
+
+	aligned, err := json.Marshal(comparison.align(actualJSON, expectedJSON))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal actual JSON: %w", err)
+	}
+	return string(aligned), nil
+}
+
+// TheResponseShouldContainTheFollowingJSON asserts that the response body contains the expected JSON. Fields
+// and array elements that are not expected are ignored, but array elements must appear in the expected order.
+// This also validates any "{{DYNAMIC_*}}" fields.
+func (f *APIFeature) TheResponseShouldContainTheFollowingJSON(expectedAPIResponse *godog.DocString) error {
+	return f.assertJSONResponse(expectedAPIResponse.Content, jsonComparison{subset: true})
+}
+
+// TheResponseShouldContainTheFollowingJSONInAnyOrder asserts that the response body contains the expected JSON,
+// ignoring fields and array elements that are not expected and the order of array elements.
+// This also validates any "{{DYNAMIC_*}}" fields.
+func (f *APIFeature) TheResponseShouldContainTheFollowingJSONInAnyOrder(expectedAPIResponse *godog.DocString) error {
+	return f.assertJSONResponse(expectedAPIResponse.Content, jsonComparison{subset: true, anyOrder: true})
+}
+
+// IShouldReceiveTheFollowingJSONResponseInAnyOrder asserts that the response body and expected response body
+// are equal, ignoring the order of array elements. This also validates any "{{DYNAMIC_*}}" fields.
+func (f *APIFeature) IShouldReceiveTheFollowingJSONResponseInAnyOrder(expectedAPIResponse *godog.DocString) error {
+	return f.assertJSONResponse(expectedAPIResponse.Content, jsonComparison{anyOrder: true})
+}
@@ -0,0 +1,311 @@
+package componenttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxJSONDifferences is the number of differences reported before the rest are summarised
+	maxJSONDifferences = 20
+	// maxJSONSnippetLines is the number of lines of a pretty-printed value shown with each difference
+	maxJSONSnippetLines = 15
+)
+
+// jsonDifferenceKind describes how a value in the actual JSON differs from the expected JSON
+type jsonDifferenceKind string
+
+const (
+	jsonMissingField      jsonDifferenceKind = "missing field"
+	jsonUnexpectedField   jsonDifferenceKind = "unexpected field"
+	jsonChangedValue      jsonDifferenceKind = "changed value"
+	jsonMissingElement    jsonDifferenceKind = "missing element"
+	jsonUnexpectedElement jsonDifferenceKind = "unexpected element"
+	jsonMovedElement      jsonDifferenceKind = "moved element"
+)
+
+// jsonAbsent is the value of a jsonDifference's expected or actual side when there is nothing on that side
+type jsonAbsent struct{}
+
+// jsonDifference is a single difference between the expected and actual JSON, at a path in buildPath notation
+type jsonDifference struct {
+	kind     jsonDifferenceKind
+	path     string
+	detail   string
+	expected interface{}
+	actual   interface{}
+}
+
+// diffJSON returns the differences between two decoded JSON documents, ordered by where they occur
+func diffJSON(expected, actual interface{}) []jsonDifference {
+	var differences []jsonDifference
+	collectJSONDifferences(expected, actual, "", &differences)
+	return differences
+}
+
+func collectJSONDifferences(expected, actual interface{}, path string, differences *[]jsonDifference) {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		for _, key := range sortedUnion(exp, act) {
+			currentPath := buildPath(path, key)
+			expValue, inExpected := exp[key]
+			actValue, inActual := act[key]
+			switch {
+			case !inActual:
+				*differences = append(*differences, jsonDifference{kind: jsonMissingField, path: currentPath, expected: expValue, actual: jsonAbsent{}})
+			case !inExpected:
+				*differences = append(*differences, jsonDifference{kind: jsonUnexpectedField, path: currentPath, expected: jsonAbsent{}, actual: actValue})
+			default:
+				collectJSONDifferences(expValue, actValue, currentPath, differences)
+			}
+		}
+		return
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+
+		collectJSONArrayDifferences(exp, act, path, differences)
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		*differences = append(*differences, jsonDifference{kind: jsonChangedValue, path: path, expected: expected, actual: actual})
+	}
+}
+
+// collectJSONArrayDifferences aligns the elements of the arrays on their longest common subsequence, so that an
+// inserted or removed element is reported once rather than as a change to every element after it. Elements that
+// only changed position are reported as moved, and the remaining unaligned elements are compared in order.
+func collectJSONArrayDifferences(expected, actual []interface{}, path string, differences *[]jsonDifference) {
+	pairs := longestCommonSubsequence(expected, actual)
+
+	var unmatchedExpected, unmatchedActual []int
+	i, j := 0, 0
+	for _, pair := range append(pairs, [2]int{len(expected), len(actual)}) {
+		for ; i < pair[0]; i++ {
+			unmatchedExpected = append(unmatchedExpected, i)
+		}
+		for ; j < pair[1]; j++ {
+			unmatchedActual = append(unmatchedActual, j)
+		}
+		i, j = pair[0]+1, pair[1]+1
+	}
+
+	usedActual := make(map[int]bool)
+	var changedExpected []int
+	for _, expIndex := range unmatchedExpected {
+		moved := false
+		for _, actIndex := range unmatchedActual {
+			if !usedActual[actIndex] && reflect.DeepEqual(expected[expIndex], actual[actIndex]) {
+				*differences = append(*differences, jsonDifference{
+					kind:     jsonMovedElement,
+					path:     buildPath(path, fmt.Sprintf("[%d]", expIndex)),
+					detail:   fmt.Sprintf("expected at index %d, found at index %d", expIndex, actIndex),
+					expected: expected[expIndex],
+					actual:   jsonAbsent{},
+				})
+				usedActual[actIndex] = true
+				moved = true
+				break
+			}
+		}
+		if !moved {
+			changedExpected = append(changedExpected, expIndex)
+		}
+	}
+
+	// pair each remaining expected element with the most similar remaining actual element after the previous pair
+	previous := -1
+	for _, expIndex := range changedExpected {
+		currentPath := buildPath(path, fmt.Sprintf("[%d]", expIndex))
+
+		best, bestScore := -1, -1
+		for _, actIndex := range unmatchedActual {
+			if usedActual[actIndex] || actIndex <= previous {
+				continue
+			}
+			if score := jsonSimilarity(expected[expIndex], actual[actIndex]); score > bestScore {
+				best, bestScore = actIndex, score
+			}
+		}
+
+		if best < 0 {
+			*differences = append(*differences, jsonDifference{kind: jsonMissingElement, path: currentPath, expected: expected[expIndex], actual: jsonAbsent{}})
+			continue
+		}
+
+		usedActual[best] = true
+		previous = best
+
+		before := len(*differences)
+		collectJSONDifferences(expected[expIndex], actual[best], currentPath, differences)
+		if best != expIndex {
+			for d := before; d < len(*differences); d++ {
+				(*differences)[d].detail = fmt.Sprintf("compared with actual index %d", best)
+			}
+		}
+	}
+
+	for _, actIndex := range unmatchedActual {
+		if usedActual[actIndex] {
+			continue
+		}
+		*differences = append(*differences, jsonDifference{
+			kind:     jsonUnexpectedElement,
+			path:     buildPath(path, fmt.Sprintf("[%d]", actIndex)),
+			expected: jsonAbsent{},
+			actual:   actual[actIndex],
+		})
+	}
+}
+
+// jsonSimilarity scores how alike two unequal JSON values are, so that a changed array element is compared with
+// the element it most likely became. Values of different types score -1 and are never compared; objects score the
+// number of fields they have in common with equal values.
+func jsonSimilarity(expected, actual interface{}) int {
+	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
+		return -1
+	}
+
+	exp, ok := expected.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+
+	act := actual.(map[string]interface{})
+	score := 0
+	for key, expValue := range exp {
+		if actValue, exists := act[key]; exists && reflect.DeepEqual(expValue, actValue) {
+			score++
+		}
+	}
+	return score
+}
+
+// longestCommonSubsequence returns the index pairs of the equal elements of the longest common subsequence
+func longestCommonSubsequence(expected, actual []interface{}) [][2]int {
+	lengths := make([][]int, len(expected)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(actual)+1)
+	}
+
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			switch {
+			case reflect.DeepEqual(expected[i], actual[j]):
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	var pairs [][2]int
+	for i, j := 0, 0; i < len(expected) && j < len(actual); {
+		switch {
+		case reflect.DeepEqual(expected[i], actual[j]):
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+func sortedUnion(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonDifferencesError describes each difference by its path, with pretty-printed snippets of the
+// expected and actual values
+func jsonDifferencesError(body string, differences []jsonDifference) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s does not match the expected JSON, %d difference(s):", body, len(differences))
+	for n, difference := range differences {
+		if n == maxJSONDifferences {
+			fmt.Fprintf(&sb, "\n\n... and %d more difference(s)", len(differences)-maxJSONDifferences)
+			break
+		}
+
+		path := difference.path
+		if path == "" {
+			path = "(root)"
+		}
+
+		fmt.Fprintf(&sb, "\n\n%s at %s", difference.kind, path)
+		if difference.detail != "" {
+			fmt.Fprintf(&sb, " (%s)", difference.detail)
+		}
+		writeJSONSnippet(&sb, "expected", difference.expected)
+		writeJSONSnippet(&sb, "actual", difference.actual)
+	}
+
+	return fmt.Errorf("%s", sb.String())
+}
+
+func writeJSONSnippet(sb *strings.Builder, label string, value interface{}) {
+	if _, absent := value.(jsonAbsent); absent {
+		return
+	}
+
+	encoded, err := json.MarshalIndent(value, "    ", "  ")
+	if err != nil {
+		encoded = []byte(fmt.Sprintf("%v", value))
+	}
+
+	lines := strings.Split(string(encoded), "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(sb, "\n  %-9s %s", label+":", lines[0])
+		return
+	}
+	if len(lines) > maxJSONSnippetLines {
+		lines = append(lines[:maxJSONSnippetLines], fmt.Sprintf("    ... (%d more lines)", len(lines)-maxJSONSnippetLines))
+	}
+
+	fmt.Fprintf(sb, "\n  %s:\n    %s", label, strings.Join(lines, "\n"))
+}
+
+// assertJSONEqual returns an error listing the differences between the expected and actual JSON documents, where
+// body describes the actual document, e.g. "response body"
+func assertJSONEqual(body, expected, actual string) error {
+	var expectedJSON, actualJSON interface{}
+
+	if err := json.Unmarshal([]byte(expected), &expectedJSON); err != nil {
+		return fmt.Errorf("failed to unmarshal expected JSON: %w", err)
+	}
+	if err := json.Unmarshal([]byte(actual), &actualJSON); err != nil {
+		return fmt.Errorf("failed to unmarshal actual JSON: %w", err)
+	}
+
+	if differences := diffJSON(expectedJSON, actualJSON); len(differences) > 0 {
+		return jsonDifferencesError(body, differences)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package componenttest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// assertResponse compares body, as the last response of an APIFeature, with the expected JSON
+func assertResponse(body, expected string) error {
+	f := &APIFeature{
+		HTTPResponse: &http.Response{Body: io.NopCloser(strings.NewReader(body))},
+	}
+	return f.IShouldReceiveTheFollowingJSONResponse(&godog.DocString{Content: expected})
+}
+
+// assertResponseContains checks that body, as the last response of an APIFeature, contains the expected JSON
+func assertResponseContains(body, expected string, anyOrder bool) error {
+	f := &APIFeature{
+		HTTPResponse: &http.Response{Body: io.NopCloser(strings.NewReader(body))},
+	}
+	if anyOrder {
+		return f.TheResponseShouldContainTheFollowingJSONInAnyOrder(&godog.DocString{Content: expected})
+	}
+	return f.TheResponseShouldContainTheFollowingJSON(&godog.DocString{Content: expected})
+}
+
+func TestJSONResponseDifferences(t *testing.T) {
+	Convey("Given a response that matches the expected JSON", t, func() {
+		body := `{"id": "9b4c3f6e-5d2a-4e8b-9c1f-2a3b4c5d6e7f", "items": [1, 2]}`
+
+		Convey("When it is compared with a dynamic value", func() {
+			err := assertResponse(body, `{"id": "{{DYNAMIC_UUID}}", "items": [1, 2]}`)
+
+			Convey("Then there is no error", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a response with a changed value", t, func() {
+		body := `{"a": 1, "b": "actual"}`
+
+		Convey("When it is compared", func() {
+			err := assertResponse(body, `{"a": 1, "b": "expected"}`)
+
+			Convey("Then the changed value is reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "response body does not match the expected JSON, 1 difference(s):")
+				So(err.Error(), ShouldContainSubstring, "changed value at b")
+				So(err.Error(), ShouldContainSubstring, `"expected"`)
+				So(err.Error(), ShouldContainSubstring, `"actual"`)
+			})
+		})
+	})
+
+	Convey("Given a response with a missing field", t, func() {
+		body := `{"id": "9b4c3f6e-5d2a-4e8b-9c1f-2a3b4c5d6e7f", "a": 1}`
+
+		Convey("When it is compared with a dynamic value", func() {
+			err := assertResponse(body, `{"id": "{{DYNAMIC_UUID}}", "a": 1, "b": 2}`)
+
+			Convey("Then only the missing field is reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "1 difference(s)")
+				So(err.Error(), ShouldContainSubstring, "missing field at b")
+			})
+		})
+	})
+
+	Convey("Given a response with an unexpected field", t, func() {
+		body := `{"a": 1, "b": 2}`
+
+		Convey("When it is compared", func() {
+			err := assertResponse(body, `{"a": 1}`)
+
+			Convey("Then the unexpected field is reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unexpected field at b")
+			})
+		})
+	})
+
+	Convey("Given a response with a field of a different type", t, func() {
+		body := `{"a": [1]}`
+
+		Convey("When it is compared", func() {
+			err := assertResponse(body, `{"a": {"b": 1}}`)
+
+			Convey("Then the field is reported as changed", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "changed value at a")
+			})
+		})
+	})
+
+	Convey("Given a response with a missing array element", t, func() {
+		body := `{"items": ["a", "c"]}`
+
+		Convey("When it is compared", func() {
+			err := assertResponse(body, `{"items": ["a", "b", "c"]}`)
+
+			Convey("Then the missing element is reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "1 difference(s)")
+				So(err.Error(), ShouldContainSubstring, "missing element at items[1]")
+			})
+		})
+	})
+
+	Convey("Given a response with an unexpected array element", t, func() {
+		body := `{"items": ["a", "x", "b"]}`
+
+		Convey("When it is compared", func() {
+			err := assertResponse(body, `{"items": ["a", "b"]}`)
+
+			Convey("Then the unexpected element is reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "1 difference(s)")
+				So(err.Error(), ShouldContainSubstring, "unexpected element at items[1]")
+			})
+		})
+	})
+
+	Convey("Given a response with a moved array element", t, func() {
+		body := `{"items": ["b", "c", "a"]}`
+
+		Convey("When it is compared", func() {
+			err := assertResponse(body, `{"items": ["a", "b", "c"]}`)
+
+			Convey("Then the moved element is reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "moved element at items[0] (expected at index 0, found at index 2)")
+			})
+		})
+	})
+
+	Convey("Given a response with an unexpected element before an element with a dynamic value", t, func() {
+		body := `{"items": [{"name": "x"}, {"id": "9b4c3f6e-5d2a-4e8b-9c1f-2a3b4c5d6e7f", "name": "a"}]}`
+
+		Convey("When it is compared", func() {
+			err := assertResponse(body, `{"items": [{"id": "{{DYNAMIC_UUID}}", "name": "a"}]}`)
+
+			Convey("Then the dynamic value is validated and only the unexpected element is reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "1 difference(s)")
+				So(err.Error(), ShouldContainSubstring, "unexpected element at items[0]")
+			})
+		})
+	})
+
+	Convey("Given a response with an invalid dynamic value", t, func() {
+		body := `{"id": "not-a-uuid", "a": 1}`
+
+		Convey("When it is compared", func() {
+			err := assertResponse(body, `{"id": "{{DYNAMIC_UUID}}", "a": 1}`)
+
+			Convey("Then the dynamic value fails validation", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "id")
+				So(err.Error(), ShouldNotContainSubstring, "difference(s)")
+			})
+		})
+	})
+
+	Convey("Given a response that does not contain an expected array element", t, func() {
+		body := `{"a": {"b": [1, 2]}}`
+
+		Convey("When it is compared as a subset", func() {
+			err := assertResponseContains(body, `{"a": {"b": [7]}}`, false)
+
+			Convey("Then the expected element is reported as missing", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "1 difference(s)")
+				So(err.Error(), ShouldContainSubstring, "missing element at a.b[0]\n  expected: 7")
+				So(err.Error(), ShouldNotContainSubstring, "changed value")
+			})
+		})
+
+		Convey("When it is compared as a subset in any order", func() {
+			err := assertResponseContains(body, `{"a": {"b": [2, 7]}}`, true)
+
+			Convey("Then the expected element is reported as missing", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "1 difference(s)")
+				So(err.Error(), ShouldContainSubstring, "missing element at a.b[1]\n  expected: 7")
+				So(err.Error(), ShouldNotContainSubstring, "changed value")
+			})
+		})
+	})
+}
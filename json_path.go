@@ -0,0 +1,259 @@
+package componenttest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// errJSONPathNotFound is returned when a JSON path does not resolve to a value in a document
+var errJSONPathNotFound = errors.New("missing field")
+
+// jsonPathSegment is a single component of a JSON path, either an object key or an array index
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath splits a path written in the notation produced by buildPath, e.g. "items[0].state",
+// into its segments
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	var segments []jsonPathSegment
+
+	rest := path
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unclosed index", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid json path %q: bad index %q", path, rest[1:end])
+			}
+			segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "."):
+			if len(segments) == 0 {
+				return nil, fmt.Errorf("invalid json path %q: unexpected '.'", path)
+			}
+			rest = rest[1:]
+			if rest == "" || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "[") {
+				return nil, fmt.Errorf("invalid json path %q: empty key", path)
+			}
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segments = append(segments, jsonPathSegment{key: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid json path %q: path is empty", path)
+	}
+
+	return segments, nil
+}
+
+// lookupJSONPath returns the value found at path in a decoded JSON document. If any part of the path
+// does not exist the returned error wraps errJSONPathNotFound.
+func lookupJSONPath(document interface{}, path string) (interface{}, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := document
+	currentPath := ""
+	for _, segment := range segments {
+		if segment.isIndex {
+			currentPath = buildPath(currentPath, fmt.Sprintf("[%d]", segment.index))
+
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("type mismatch at %s: expected array, got %T", currentPath, current)
+			}
+			if segment.index >= len(arr) {
+				return nil, fmt.Errorf("%w at %s", errJSONPathNotFound, currentPath)
+			}
+			current = arr[segment.index]
+			continue
+		}
+
+		currentPath = buildPath(currentPath, segment.key)
+
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("type mismatch at %s: expected object, got %T", currentPath, current)
+		}
+		value, exists := obj[segment.key]
+		if !exists {
+			return nil, fmt.Errorf("%w at %s", errJSONPathNotFound, currentPath)
+		}
+		current = value
+	}
+
+	return current, nil
+}
+
+// jsonValueToString returns strings as they are and any other JSON value in its compact encoded form. Integers are
+// given exactly as in the response, and other numbers in their shortest form, e.g. "1.5" for 1.50.
+func jsonValueToString(value interface{}) (string, error) {
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+
+	encoded, err := json.Marshal(convertJSONNumbers(value, true))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal json value: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// convertJSONNumbers returns value with every json.Number converted to a float64, as dynamic validators expect,
+// except for integers when keepIntegers is set so that they keep their precision
+func convertJSONNumbers(value interface{}, keepIntegers bool) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if keepIntegers && !strings.ContainsAny(v.String(), ".eE") {
+			return v
+		}
+		if number, err := v.Float64(); err == nil {
+			return number
+		}
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			converted[key] = convertJSONNumbers(element, keepIntegers)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, element := range v {
+			converted[i] = convertJSONNumbers(element, keepIntegers)
+		}
+		return converted
+	}
+	return value
+}
+
+// lookupResponseJSONPath returns the value at path in the body of the last response
+func (f *APIFeature) lookupResponseJSONPath(path string) (interface{}, error) {
+	document, err := f.readResponseJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return lookupJSONPath(document, path)
+}
+
+// TheJSONPathShouldBe asserts that the value at the JSON path of the response body is equal to expected.
+// Non-string values are compared using their JSON encoding, e.g. "3", "true" or "null". Expected may also
+// be a dynamic placeholder such as "{{DYNAMIC_UUID}}".
+func (f *APIFeature) TheJSONPathShouldBe(path, expected string) error {
+	value, err := f.lookupResponseJSONPath(path)
+	if err != nil {
+		return err
+	}
+
+	expected, err = f.Variables.Interpolate(expected)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(expected, "{{DYNAMIC_") {
+		return f.dynamicValidatorRegistry().validateDynamicValue(convertJSONNumbers(value, false), expected, path)
+	}
+
+	actual, err := jsonValueToString(value)
+	if err != nil {
+		return err
+	}
+
+	if actual != expected {
+		return fmt.Errorf("field at %s value %q does not equal %q", path, actual, expected)
+	}
+	return nil
+}
+
+// TheJSONPathShouldExist asserts that the JSON path resolves to a value in the response body
+func (f *APIFeature) TheJSONPathShouldExist(path string) error {
+	_, err := f.lookupResponseJSONPath(path)
+	return err
+}
+
+// TheJSONPathShouldNotExist asserts that the JSON path does not resolve to a value in the response body
+func (f *APIFeature) TheJSONPathShouldNotExist(path string) error {
+	value, err := f.lookupResponseJSONPath(path)
+	if errors.Is(err, errJSONPathNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	actual, err := jsonValueToString(value)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("field at %s exists with value %q", path, actual)
+}
+
+// TheJSONPathShouldHaveLength asserts the number of elements of an array, keys of an object or characters
+// of a string found at the JSON path of the response body
+func (f *APIFeature) TheJSONPathShouldHaveLength(path string, expected int) error {
+	value, err := f.lookupResponseJSONPath(path)
+	if err != nil {
+		return err
+	}
+
+	var length int
+	switch v := value.(type) {
+	case []interface{}:
+		length = len(v)
+	case map[string]interface{}:
+		length = len(v)
+	case string:
+		length = utf8.RuneCountInString(v)
+	default:
+		return fmt.Errorf("field at %s has no length: %T", path, value)
+	}
+
+	if length != expected {
+		return fmt.Errorf("length mismatch at %s: expected %d, got %d", path, expected, length)
+	}
+	return nil
+}
+
+// TheJSONPathShouldMatchTheRegex asserts that the value at the JSON path of the response body matches the
+// regular expression. Non-string values are matched against their JSON encoding.
+func (f *APIFeature) TheJSONPathShouldMatchTheRegex(path, expression string) error {
+	re, err := regexp.Compile(expression)
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", expression, err)
+	}
+
+	value, err := f.lookupResponseJSONPath(path)
+	if err != nil {
+		return err
+	}
+
+	actual, err := jsonValueToString(value)
+	if err != nil {
+		return err
+	}
+
+	if !re.MatchString(actual) {
+		return fmt.Errorf("field at %s value %q does not match regex %q", path, actual, expression)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package componenttest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newResponseFeature returns an APIFeature whose last response has the body
+func newResponseFeature(body string) *APIFeature {
+	return &APIFeature{
+		HTTPResponse: &http.Response{Body: io.NopCloser(strings.NewReader(body))},
+		Variables:    NewScenarioVariables(),
+	}
+}
+
+func TestTheJSONPathShouldBe(t *testing.T) {
+	Convey("Given a response with a large integer and a decimal", t, func() {
+		f := newResponseFeature(`{"big": 12345678901234567890, "price": 1.50, "count": 3}`)
+
+		Convey("When the large integer is asserted", func() {
+			err := f.TheJSONPathShouldBe("big", "12345678901234567890")
+
+			Convey("Then it keeps its precision", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When the large integer is stored as a variable", func() {
+			So(f.IStoreTheJSONPathAs("big", "big"), ShouldBeNil)
+
+			Convey("Then the variable keeps its precision", func() {
+				value, _ := f.Variables.Get("big")
+				So(value, ShouldEqual, "12345678901234567890")
+			})
+		})
+
+		Convey("When the decimal is asserted", func() {
+			err := f.TheJSONPathShouldBe("price", "1.5")
+
+			Convey("Then it is compared in its shortest form", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When the numbers are asserted with dynamic values", func() {
+			Convey("Then they are validated as numbers", func() {
+				So(f.TheJSONPathShouldBe("count", "{{DYNAMIC_INT}}"), ShouldBeNil)
+				So(f.TheJSONPathShouldBe("price", "{{DYNAMIC_NUMBER}}"), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a response with data after the JSON document", t, func() {
+		f := newResponseFeature(`{"id": 1} {"id": 2}`)
+
+		Convey("When a JSON path is asserted", func() {
+			err := f.TheJSONPathShouldBe("id", "1")
+
+			Convey("Then the body is rejected", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unexpected data after the top-level value")
+			})
+		})
+	})
+}
@@ -0,0 +1,116 @@
+package componenttest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cucumber/godog"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+)
+
+// inlineSchemaURL is the location an inline schema is registered under. Being relative, it resolves against
+// the working directory so that any "$ref" in an inline schema can refer to local schema files.
+const inlineSchemaURL = "inline-schema.json"
+
+// TheResponseShouldConformToTheJSONSchema asserts that the response body is valid against the JSON schema
+// file at schemaPath. Schemas default to draft 2020-12 and may "$ref" other local schema files.
+func (f *APIFeature) TheResponseShouldConformToTheJSONSchema(schemaPath string) error {
+	compiler := newJSONSchemaCompiler()
+
+	schema, err := compiler.Compile(schemaPath)
+	if err != nil {
+		return fmt.Errorf("failed to compile JSON schema %q: %w", schemaPath, err)
+	}
+
+	return f.validateResponseAgainstSchema(schema)
+}
+
+// TheResponseShouldConformToTheFollowingJSONSchema asserts that the response body is valid against the
+// JSON schema provided. Schemas default to draft 2020-12 and may "$ref" local schema files.
+func (f *APIFeature) TheResponseShouldConformToTheFollowingJSONSchema(schemaDoc *godog.DocString) error {
+	document, err := jsonschema.UnmarshalJSON(strings.NewReader(schemaDoc.Content))
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal JSON schema: %w", err)
+	}
+
+	compiler := newJSONSchemaCompiler()
+	if err := compiler.AddResource(inlineSchemaURL, document); err != nil {
+		return fmt.Errorf("failed to add JSON schema: %w", err)
+	}
+
+	schema, err := compiler.Compile(inlineSchemaURL)
+	if err != nil {
+		return fmt.Errorf("failed to compile JSON schema: %w", err)
+	}
+
+	return f.validateResponseAgainstSchema(schema)
+}
+
+func newJSONSchemaCompiler() *jsonschema.Compiler {
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	return compiler
+}
+
+// validateResponseAgainstSchema validates the response body against schema, returning an error that
+// lists every violation by the JSON pointer of the offending field
+func (f *APIFeature) validateResponseAgainstSchema(schema *jsonschema.Schema) error {
+	body, err := f.readResponseBody()
+	if err != nil {
+		return err
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response body as JSON: %w", err)
+	}
+
+	err = schema.Validate(instance)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return fmt.Errorf("failed to validate response against JSON schema: %w", err)
+	}
+
+	return schemaViolationsError(validationErr)
+}
+
+// schemaViolationsError flattens a validation error into one line per violating field, using the same
+// "field at ... is not valid" wording as the dynamic validators
+func schemaViolationsError(validationErr *jsonschema.ValidationError) error {
+	var violations []string
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil || isSchemaGroupError(unit.Error.Kind) {
+			continue
+		}
+
+		pointer := unit.InstanceLocation
+		if pointer == "" {
+			pointer = "(root)"
+		}
+		violations = append(violations, fmt.Sprintf("field at %s is not valid: %s", pointer, unit.Error))
+	}
+
+	if len(violations) == 0 {
+		return fmt.Errorf("response does not conform to JSON schema: %w", validationErr)
+	}
+
+	return fmt.Errorf("response does not conform to JSON schema:\n%s", strings.Join(violations, "\n"))
+}
+
+// isSchemaGroupError reports whether an error only groups the errors of nested keywords or references,
+// which are reported individually
+func isSchemaGroupError(errorKind jsonschema.ErrorKind) bool {
+	switch errorKind.(type) {
+	case *kind.Group, *kind.Reference, *kind.Schema:
+		return true
+	}
+	return false
+}
@@ -106,6 +106,8 @@ type KafkaScenario struct {
 	mu           sync.Mutex
 	KafkaFeature *KafkaFeature
 	topics       map[string]*kafkaScenarioTopic
+	// Variables, when set, replaces "{{name}}" references in event documents with scenario variables
+	Variables *ScenarioVariables
 }
 
 type kafkaScenarioTopic struct {
@@ -166,8 +168,13 @@ func (ks *KafkaScenario) thisEncodedEventIsQueued(ctx context.Context, topic, en
 		encoder = compactJSON
 	}
 
+	content, err := ks.Variables.Interpolate(document.Content)
+	if err != nil {
+		return err
+	}
+
 	// encode message
-	wireMsg, err := encoder([]byte(document.Content))
+	wireMsg, err := encoder([]byte(content))
 	if err != nil {
 		return err
 	}
@@ -192,8 +199,13 @@ func (ks *KafkaScenario) thisEncodedEventIsProduced(ctx context.Context, topic,
 	}
 	scenarioTopic := ks.getScenarioTopic(topic)
 
+	content, err := ks.Variables.Interpolate(document.Content)
+	if err != nil {
+		return err
+	}
+
 	// encode expected document
-	wantedEvent, err := encoder([]byte(document.Content))
+	wantedEvent, err := encoder([]byte(content))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,144 @@
+package componenttest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestLatency is how long a request took to be answered
+type RequestLatency struct {
+	Method   string
+	Path     string
+	Duration time.Duration
+}
+
+// LatencyRecorder records how long every request made through the APIFeatures it is set on takes, and reports the
+// requests that were slower than its SlowThreshold
+type LatencyRecorder struct {
+	// SlowThreshold is the duration above which a request is reported as slow
+	SlowThreshold time.Duration
+	mu            sync.Mutex
+	requests      []RequestLatency
+}
+
+// NewLatencyRecorder returns a LatencyRecorder that reports requests taking longer than slowThreshold as slow
+func NewLatencyRecorder(slowThreshold time.Duration) *LatencyRecorder {
+	return &LatencyRecorder{
+		SlowThreshold: slowThreshold,
+	}
+}
+
+// Record records the duration of a request
+func (r *LatencyRecorder) Record(method, path string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, RequestLatency{Method: method, Path: path, Duration: duration})
+}
+
+// SlowRequests returns the requests that took longer than SlowThreshold, slowest first
+func (r *LatencyRecorder) SlowRequests() []RequestLatency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var slow []RequestLatency
+	for _, request := range r.requests {
+		if request.Duration > r.SlowThreshold {
+			slow = append(slow, request)
+		}
+	}
+
+	sort.SliceStable(slow, func(i, j int) bool {
+		return slow[i].Duration > slow[j].Duration
+	})
+	return slow
+}
+
+// Report returns a summary of the slow requests, grouped by method and path with the slowest first
+func (r *LatencyRecorder) Report() string {
+	slow := r.SlowRequests()
+	if len(slow) == 0 {
+		return ""
+	}
+
+	type group struct {
+		name    string
+		count   int
+		slowest time.Duration
+		total   time.Duration
+	}
+
+	var groups []*group
+	byName := make(map[string]*group)
+	for _, request := range slow {
+		name := request.Method + " " + request.Path
+		g, ok := byName[name]
+		if !ok {
+			g = &group{name: name, slowest: request.Duration}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		g.count++
+		g.total += request.Duration
+	}
+
+	width := 0
+	for _, g := range groups {
+		width = max(width, len(g.name))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Slow requests (over %v): %d\n", r.SlowThreshold, len(slow))
+	for _, g := range groups {
+		fmt.Fprintf(&sb, "  %-*s  %d request(s), slowest %v, mean %v\n", width, g.name, g.count,
+			g.slowest.Round(time.Millisecond), (g.total / time.Duration(g.count)).Round(time.Millisecond))
+	}
+	return sb.String()
+}
+
+// recordLatency records how long req took in the feature's LatencyRecorder, if it has one
+func (f *APIFeature) recordLatency(req *http.Request, duration time.Duration) {
+	if f.Latencies != nil {
+		f.Latencies.Record(req.Method, req.URL.Path, duration)
+	}
+}
+
+// checkLatencyBudget returns an error if the last request took longer than the scenario's latency budget
+func (f *APIFeature) checkLatencyBudget(req *http.Request) error {
+	if f.latencyBudget > 0 && f.ResponseTime > f.latencyBudget {
+		return fmt.Errorf("%s %s took %v, over the latency budget of %v", req.Method, req.URL.Path,
+			f.ResponseTime.Round(time.Millisecond), f.latencyBudget)
+	}
+	return nil
+}
+
+// everyResponseShouldBeReceivedWithin sets a latency budget that every following request in the scenario must meet
+func (f *APIFeature) everyResponseShouldBeReceivedWithin(amount int, unit string) error {
+	f.latencyBudget = latencyDuration(amount, unit)
+	return nil
+}
+
+// TheResponseShouldHaveBeenReceivedWithin asserts that the last request, including any redirects followed, was
+// answered within the duration
+func (f *APIFeature) TheResponseShouldHaveBeenReceivedWithin(amount int, unit string) error {
+	if f.HTTPResponse == nil {
+		return fmt.Errorf("no response has been received")
+	}
+
+	if limit := latencyDuration(amount, unit); f.ResponseTime > limit {
+		return fmt.Errorf("expected the response to be received within %v, but it took %v", limit, f.ResponseTime.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// latencyDuration returns the duration of amount of unit, which is "milliseconds", "second" or "seconds"
+func latencyDuration(amount int, unit string) time.Duration {
+	if unit == "milliseconds" {
+		return time.Duration(amount) * time.Millisecond
+	}
+	return time.Duration(amount) * time.Second
+}
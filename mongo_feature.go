@@ -20,6 +20,8 @@ type MongoFeature struct {
 	Server   *testMongo.MongoDBContainer
 	Client   mongo.Client
 	Database *mongo.Database
+	// Variables, when set, replaces "{{name}}" references in step arguments with scenario variables
+	Variables *ScenarioVariables
 }
 
 // MongoOptions contains a set of options required to create a new MongoFeature
@@ -219,9 +221,14 @@ func (m *MongoFeature) TheFollowingDocumentExistsInTheCollection(collectionName
 
 	collection := m.Database.Collection(collectionName)
 
+	content, err := m.Variables.Interpolate(document.Content)
+	if err != nil {
+		return err
+	}
+
 	var documentJSON map[string]interface{}
 
-	if err := json.Unmarshal([]byte(document.Content), &documentJSON); err != nil {
+	if err := json.Unmarshal([]byte(content), &documentJSON); err != nil {
 		return err
 	}
 	if _, err := collection.InsertOne(ctx, documentJSON); err != nil {
@@ -235,10 +242,15 @@ func (m *MongoFeature) theDocumentWithSetToDoesNotExistInTheCollection(key, valu
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	value, err := m.Variables.Interpolate(value)
+	if err != nil {
+		return err
+	}
+
 	collection := m.Database.Collection(collectionName)
 	var documentJSON interface{}
 
-	err := collection.FindOne(ctx, bson.M{key: value}).Decode(&documentJSON)
+	err = collection.FindOne(ctx, bson.M{key: value}).Decode(&documentJSON)
 
 	if err == mongo.ErrNoDocuments {
 		return nil
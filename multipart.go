@@ -0,0 +1,140 @@
+package componenttest
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cucumber/godog"
+)
+
+// multipart part types, given in the "type" column of a multipart table
+const (
+	multipartField     = "field"
+	multipartFile      = "file"
+	multipartGenerated = "generated"
+)
+
+// generatedContent is repeated to fill generated file parts, so that their content is predictable
+const generatedContent = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+/*
+IRequestWithTheMultipartForm makes a request to the provided path with the current headers and a multipart/form-data
+body built from the table, in order. The Content-Type header, including the boundary, is set for this request.
+
+Each row is a part with a name, a type and a value:
+  - field: a form field with the value
+  - file: a file part with the content of the file at the value, relative to the directory the tests are run from
+  - generated: a file part of the size given by the value, e.g. "512", "64KB" or "5MB", named "<name>.bin"
+
+Table should look like:
+| name  | type      | value                               |
+| title | field     | CPIH                                |
+| file  | file      | features/fixtures/observations.json |
+| chunk | generated | 5MB                                 |
+*/
+func (f *APIFeature) IRequestWithTheMultipartForm(method, path string, table *godog.Table) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue
+		}
+		if len(row.Cells) != 3 {
+			return fmt.Errorf("table row %d has %d cells, expected a name, a type and a value", i, len(row.Cells))
+		}
+
+		name, err := f.Variables.Interpolate(row.Cells[0].Value)
+		if err != nil {
+			return err
+		}
+		value, err := f.Variables.Interpolate(row.Cells[2].Value)
+		if err != nil {
+			return err
+		}
+
+		if err := writeMultipartPart(writer, name, row.Cells[1].Value, value); err != nil {
+			return fmt.Errorf("table row %d: %w", i, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write multipart body: %w", err)
+	}
+
+	headers := map[string]string{"Content-Type": writer.FormDataContentType()}
+	return f.makeRequestWithHeaders(method, path, body.Bytes(), headers)
+}
+
+func writeMultipartPart(writer *multipart.Writer, name, partType, value string) error {
+	switch partType {
+	case multipartField:
+		return writer.WriteField(name, value)
+	case multipartFile:
+		content, err := os.ReadFile(value)
+		if err != nil {
+			return fmt.Errorf("failed to read file part %q: %w", value, err)
+		}
+		return writeMultipartFile(writer, name, filepath.Base(value), content)
+	case multipartGenerated:
+		size, err := parseByteSize(value)
+		if err != nil {
+			return err
+		}
+		return writeMultipartFile(writer, name, name+".bin", generateContent(size))
+	default:
+		return fmt.Errorf("unknown multipart part type %q, expected %s, %s or %s", partType, multipartField, multipartFile, multipartGenerated)
+	}
+}
+
+// writeMultipartFile writes a file part, with a Content-Type guessed from the extension of filename
+func writeMultipartFile(writer *multipart.Writer, name, filename string, content []byte) error {
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": name, "filename": filename}))
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create file part %q: %w", name, err)
+	}
+
+	_, err = part.Write(content)
+	return err
+}
+
+// parseByteSize parses a size in bytes, optionally with a B, KB or MB suffix, where a kilobyte is 1024 bytes
+func parseByteSize(size string) (int, error) {
+	number, multiplier := strings.ToUpper(strings.TrimSpace(size)), 1
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"B", 1}} {
+		if strings.HasSuffix(number, unit.suffix) {
+			number, multiplier = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix)), unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q, expected a number of bytes such as 512, 64KB or 5MB", size)
+	}
+	return n * multiplier, nil
+}
+
+// generateContent returns size bytes of predictable content
+func generateContent(size int) []byte {
+	return bytes.Repeat([]byte(generatedContent), size/len(generatedContent)+1)[:size]
+}
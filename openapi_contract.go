@@ -0,0 +1,254 @@
+package componenttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIContract validates the requests and responses made through an APIFeature against an OpenAPI 2
+// (swagger) or OpenAPI 3 document and records which of the document's operations have been exercised.
+type OpenAPIContract struct {
+	mu        sync.Mutex
+	doc       *openapi3.T
+	router    routers.Router
+	exercised map[string]int
+}
+
+// NewOpenAPIContract loads the OpenAPI 2 or 3 document, in YAML or JSON, at path
+func NewOpenAPIContract(path string) (*OpenAPIContract, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI document %q: %w", path, err)
+	}
+
+	var version struct {
+		Swagger string `yaml:"swagger"`
+	}
+	if err := yaml.Unmarshal(data, &version); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal OpenAPI document %q: %w", path, err)
+	}
+
+	var doc *openapi3.T
+	if version.Swagger != "" {
+		doc, err = loadSwaggerDocument(data)
+	} else {
+		loader := openapi3.NewLoader()
+		loader.IsExternalRefsAllowed = true
+		doc, err = loader.LoadFromFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI document %q: %w", path, err)
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document %q: %w", path, err)
+	}
+
+	// requests may be made to any host, e.g. "http://foo" in-process, so only the base path of each server is kept
+	doc.Servers, err = serverBasePaths(doc.Servers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid servers in OpenAPI document %q: %w", path, err)
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router for OpenAPI document %q: %w", path, err)
+	}
+
+	return &OpenAPIContract{
+		doc:       doc,
+		router:    router,
+		exercised: make(map[string]int),
+	}, nil
+}
+
+// loadSwaggerDocument converts an OpenAPI 2 document to OpenAPI 3
+func loadSwaggerDocument(data []byte) (*openapi3.T, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc2 openapi2.T
+	if err := json.Unmarshal(jsonData, &doc2); err != nil {
+		return nil, err
+	}
+
+	return openapi2conv.ToV3(&doc2)
+}
+
+// serverBasePaths returns a server for each distinct base path of servers, without scheme or host
+func serverBasePaths(servers openapi3.Servers) (openapi3.Servers, error) {
+	seen := make(map[string]bool)
+	var basePaths openapi3.Servers
+	for _, server := range servers {
+		basePath, err := server.BasePath()
+		if err != nil {
+			return nil, err
+		}
+		if seen[basePath] {
+			continue
+		}
+		seen[basePath] = true
+		basePaths = append(basePaths, &openapi3.Server{URL: basePath})
+	}
+	return basePaths, nil
+}
+
+// validate checks the request and its response against the matching operation of the contract. If
+// validateRequest is false only the response is checked, e.g. when a scenario deliberately sends a bad request.
+func (c *OpenAPIContract) validate(req *http.Request, requestBody []byte, resp *http.Response, responseBody []byte, validateRequest bool) error {
+	ctx := context.Background()
+
+	req = req.Clone(ctx)
+	req.Body = io.NopCloser(bytes.NewReader(requestBody))
+
+	route, pathParams, err := c.router.FindRoute(req)
+	if err != nil {
+		return fmt.Errorf("%s %s does not match any operation in the OpenAPI contract: %w", req.Method, req.URL.Path, err)
+	}
+
+	operation := route.Method + " " + route.Path
+	c.recordExercised(operation)
+
+	options := &openapi3filter.Options{
+		MultiError:            true,
+		IncludeResponseStatus: true,
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+	}
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    options,
+	}
+
+	var violations []error
+	if validateRequest {
+		if err := openapi3filter.ValidateRequest(ctx, requestInput); err != nil {
+			violations = append(violations, fmt.Errorf("request does not match the OpenAPI contract for %s: %w", operation, err))
+		}
+	}
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Options:                options,
+	}
+	responseInput.SetBodyBytes(responseBody)
+
+	if err := openapi3filter.ValidateResponse(ctx, responseInput); err != nil {
+		violations = append(violations, fmt.Errorf("%d response does not match the OpenAPI contract for %s: %w", resp.StatusCode, operation, err))
+	}
+
+	return errors.Join(violations...)
+}
+
+func (c *OpenAPIContract) recordExercised(operation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.exercised[operation]++
+}
+
+// Operations returns every operation in the contract as "METHOD /path", sorted by path and method
+func (c *OpenAPIContract) Operations() []string {
+	var operations []string
+	for path, pathItem := range c.doc.Paths.Map() {
+		for method := range pathItem.Operations() {
+			operations = append(operations, method+" "+path)
+		}
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		methodI, pathI, _ := strings.Cut(operations[i], " ")
+		methodJ, pathJ, _ := strings.Cut(operations[j], " ")
+		if pathI != pathJ {
+			return pathI < pathJ
+		}
+		return methodI < methodJ
+	})
+	return operations
+}
+
+// UnexercisedOperations returns the operations in the contract that no request has been made to
+func (c *OpenAPIContract) UnexercisedOperations() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var unexercised []string
+	for _, operation := range c.Operations() {
+		if c.exercised[operation] == 0 {
+			unexercised = append(unexercised, operation)
+		}
+	}
+	return unexercised
+}
+
+// Report returns a summary of the contract's operation coverage, listing any operations that were never
+// exercised
+func (c *OpenAPIContract) Report() string {
+	operations := c.Operations()
+	unexercised := c.UnexercisedOperations()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "OpenAPI contract coverage: %d of %d operations exercised\n", len(operations)-len(unexercised), len(operations))
+	if len(unexercised) > 0 {
+		sb.WriteString("Operations never exercised:\n")
+		for _, operation := range unexercised {
+			fmt.Fprintf(&sb, "  %s\n", operation)
+		}
+	}
+	return sb.String()
+}
+
+// validateOpenAPIContract validates the last request and response against the OpenAPI contract, if one is set
+func (f *APIFeature) validateOpenAPIContract(req *http.Request, requestBody []byte) error {
+	if f.OpenAPIContract == nil || f.skipOpenAPIContract {
+		return nil
+	}
+
+	responseBody, err := f.readResponseBody()
+	if err != nil {
+		return err
+	}
+
+	return f.OpenAPIContract.validate(req, requestBody, f.HTTPResponse, responseBody, !f.skipOpenAPIRequestValidation)
+}
+
+// requestsAreNotValidatedAgainstTheOpenAPIContract allows a scenario to deliberately send requests that break
+// the contract, e.g. to test validation errors. Responses are still validated.
+func (f *APIFeature) requestsAreNotValidatedAgainstTheOpenAPIContract() error {
+	f.skipOpenAPIRequestValidation = true
+	return nil
+}
+
+// theOpenAPIContractIsNotEnforced disables contract validation for the rest of the scenario, e.g. to request
+// paths that are not part of the contract
+func (f *APIFeature) theOpenAPIContractIsNotEnforced() error {
+	f.skipOpenAPIContract = true
+	return nil
+}
@@ -0,0 +1,176 @@
+package componenttest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPollingInitialInterval = 100 * time.Millisecond
+	defaultPollingMaxInterval     = 2 * time.Second
+	defaultPollingMultiplier      = 2
+	// maxPollingHistory is the number of attempts described when polling times out
+	maxPollingHistory = 20
+)
+
+// PollingOptions configure the backoff between the attempts of the polling steps, e.g. "I GET "/jobs/1" until the
+// HTTP status code is 200 within 30 seconds". The interval starts at InitialInterval and is multiplied by
+// Multiplier after each attempt, up to MaxInterval. Unset options take their defaults.
+type PollingOptions struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+}
+
+// pollAttempt describes a single request made while polling
+type pollAttempt struct {
+	elapsed time.Duration
+	status  int
+	err     error
+}
+
+func (a pollAttempt) String() string {
+	elapsed := a.elapsed.Round(time.Millisecond)
+	if a.status == 0 {
+		return fmt.Sprintf("+%v: %v", elapsed, a.err)
+	}
+	return fmt.Sprintf("+%v: %d %v", elapsed, a.status, a.err)
+}
+
+// pollingOptions returns the feature's polling options with any unset option defaulted
+func (f *APIFeature) pollingOptions() PollingOptions {
+	opts := PollingOptions{}
+	if f.Polling != nil {
+		opts = *f.Polling
+	}
+
+	if opts.InitialInterval <= 0 {
+		opts.InitialInterval = defaultPollingInitialInterval
+	}
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = defaultPollingMaxInterval
+	}
+	if opts.Multiplier < 1 {
+		opts.Multiplier = defaultPollingMultiplier
+	}
+	return opts
+}
+
+// IGetUntilTheJSONPathIs repeats a GET request to the provided path until the value at the JSON path of the
+// response body is equal to expected, as in "the JSON path ... should be ...", or the timeout is reached
+func (f *APIFeature) IGetUntilTheJSONPathIs(path, jsonPath, expected string, timeoutSeconds int) error {
+	return f.poll("GET", path, time.Duration(timeoutSeconds)*time.Second, func() error {
+		return f.TheJSONPathShouldBe(jsonPath, expected)
+	})
+}
+
+// IGetUntilTheHTTPStatusCodeIs repeats a GET request to the provided path until the response has the expected
+// status code or the timeout is reached
+func (f *APIFeature) IGetUntilTheHTTPStatusCodeIs(path, expectedCodeStr string, timeoutSeconds int) error {
+	expectedCode, err := strconv.Atoi(expectedCodeStr)
+	if err != nil {
+		return err
+	}
+
+	return f.poll("GET", path, time.Duration(timeoutSeconds)*time.Second, func() error {
+		if f.HTTPResponse.StatusCode != expectedCode {
+			return fmt.Errorf("expected status code %d", expectedCode)
+		}
+		return nil
+	})
+}
+
+// TheEndpointShouldReportWithin repeats a GET request to the health endpoint at the provided path until its overall
+// status is expected, e.g. "CRITICAL", or the timeout is reached. On timeout the error lists the status observed by
+// each attempt.
+func (f *APIFeature) TheEndpointShouldReportWithin(path, expectedStatus string, timeoutSeconds int) error {
+	return f.poll("GET", path, time.Duration(timeoutSeconds)*time.Second, func() error {
+		health, err := f.readHealthResponse()
+		if err != nil {
+			return err
+		}
+
+		if health.Status != expectedStatus {
+			return fmt.Errorf("status is %q", health.Status)
+		}
+		return nil
+	})
+}
+
+// TheEndpointShouldReportForCheckWithin repeats a GET request to the health endpoint at the provided path until the
+// check with the name has the expected status or the timeout is reached. On timeout the error lists the status of the
+// check observed by each attempt.
+func (f *APIFeature) TheEndpointShouldReportForCheckWithin(path, expectedStatus, checkName string, timeoutSeconds int) error {
+	return f.poll("GET", path, time.Duration(timeoutSeconds)*time.Second, func() error {
+		health, err := f.readHealthResponse()
+		if err != nil {
+			return err
+		}
+
+		for _, check := range health.Checks {
+			if check.Name != checkName {
+				continue
+			}
+			if check.Status != expectedStatus {
+				return fmt.Errorf("check %q is %q", checkName, check.Status)
+			}
+			return nil
+		}
+		return fmt.Errorf("check %q is not reported", checkName)
+	})
+}
+
+// poll makes the request until condition returns nil for its response or the timeout is reached, backing off
+// between attempts. The last response is kept in HTTPResponse. On timeout the error describes every attempt.
+func (f *APIFeature) poll(method, path string, timeout time.Duration, condition func() error) error {
+	opts := f.pollingOptions()
+	interval := opts.InitialInterval
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	var attempts []pollAttempt
+	for {
+		attempt := pollAttempt{}
+
+		attempt.err = f.makeRequest(method, path, nil)
+		if attempt.err == nil {
+			attempt.status = f.HTTPResponse.StatusCode
+			attempt.err = condition()
+			if attempt.err == nil {
+				return nil
+			}
+		}
+
+		attempt.elapsed = time.Since(start)
+		attempts = append(attempts, attempt)
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return pollingTimeoutError(method, path, timeout, attempts)
+		}
+
+		time.Sleep(min(interval, remaining))
+		interval = min(time.Duration(float64(interval)*opts.Multiplier), opts.MaxInterval)
+	}
+}
+
+// pollingTimeoutError describes the attempts made before polling timed out, omitting the earliest if there are many
+func pollingTimeoutError(method, path string, timeout time.Duration, attempts []pollAttempt) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s %s did not meet the condition within %v after %d attempt(s):", method, path, timeout, len(attempts))
+
+	first := 0
+	if len(attempts) > maxPollingHistory {
+		first = len(attempts) - maxPollingHistory
+		fmt.Fprintf(&sb, "\n  ... %d earlier attempt(s) omitted", first)
+	}
+	for i := first; i < len(attempts); i++ {
+		fmt.Fprintf(&sb, "\n  attempt %d %v", i+1, attempts[i])
+	}
+
+	return fmt.Errorf("%s", sb.String())
+}
@@ -0,0 +1,183 @@
+package componenttest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cucumber/godog"
+)
+
+// maxRedirects is the number of redirects followed for a single request before giving up
+const maxRedirects = 10
+
+// redirect is a redirect response received while making a request
+type redirect struct {
+	status   int
+	location string
+}
+
+func (r redirect) String() string {
+	return fmt.Sprintf("%d %s", r.status, r.location)
+}
+
+// target returns the method and path of the request that follows the redirect from req. Like a browser, a 301 or
+// 302 redirect of a POST, and a 303 redirect of anything but a HEAD, is followed with a GET. Redirects to another
+// host are not followed.
+func (r redirect) target(req *http.Request) (method, path string, ok bool) {
+	location, err := url.Parse(r.location)
+	if err != nil {
+		return "", "", false
+	}
+
+	next := req.URL.ResolveReference(location)
+	if next.Host != req.URL.Host {
+		return "", "", false
+	}
+
+	method = req.Method
+	switch r.status {
+	case http.StatusMovedPermanently, http.StatusFound:
+		if method == http.MethodPost {
+			method = http.MethodGet
+		}
+	case http.StatusSeeOther:
+		if method != http.MethodHead {
+			method = http.MethodGet
+		}
+	}
+	return method, next.RequestURI(), true
+}
+
+// pathFromBaseURL returns path relative to BaseURL, which may include a path prefix, as newRequest expects
+func (f *APIFeature) pathFromBaseURL(path string) string {
+	base, err := url.Parse(f.BaseURL)
+	if f.BaseURL == "" || err != nil {
+		return path
+	}
+	return strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+}
+
+// redirectsAreFollowed follows redirects for the rest of the scenario
+func (f *APIFeature) redirectsAreFollowed() error {
+	f.followRedirects = true
+	return nil
+}
+
+func (f *APIFeature) followingRedirects() bool {
+	return f.FollowRedirects || f.followRedirects
+}
+
+// recordRedirect records the response to req if it is a redirect, returning the redirect and whether it was one
+func (f *APIFeature) recordRedirect(req *http.Request) (redirect, bool) {
+	switch f.HTTPResponse.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return redirect{}, false
+	}
+
+	location := f.HTTPResponse.Header.Get("Location")
+	if location == "" {
+		return redirect{}, false
+	}
+
+	r := redirect{status: f.HTTPResponse.StatusCode, location: location}
+	f.redirects = append(f.redirects, r)
+	return r, true
+}
+
+// checkRedirectLoop returns visited with the next request added, or an error if the request has already been made
+// or too many redirects have been followed
+func checkRedirectLoop(visited []string, next string) ([]string, error) {
+	visited = append(visited, next)
+
+	for _, request := range visited[:len(visited)-1] {
+		if request == next {
+			return visited, fmt.Errorf("redirect loop detected: %s", strings.Join(visited, " -> "))
+		}
+	}
+	if len(visited) > maxRedirects+1 {
+		return visited, fmt.Errorf("stopped after %d redirects: %s", maxRedirects, strings.Join(visited, " -> "))
+	}
+	return visited, nil
+}
+
+// IShouldBeRedirectedToWithStatus asserts that the last request was redirected to the location by a response with
+// the status code, whether or not the redirect was followed
+func (f *APIFeature) IShouldBeRedirectedToWithStatus(location, expectedCodeStr string) error {
+	expectedCode, err := strconv.Atoi(expectedCodeStr)
+	if err != nil {
+		return err
+	}
+
+	location, err = f.Variables.Interpolate(location)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range f.redirects {
+		if r.location == location && r.status == expectedCode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("expected a redirect to %q with status %d, got %s", location, expectedCode, f.describeRedirects())
+}
+
+/*
+TheRedirectChainShouldBe asserts that the last request was redirected by exactly the responses in the table, in order
+
+Table should look like:
+| status | location     |
+| 301    | /v2/datasets |
+| 308    | /datasets    |
+*/
+func (f *APIFeature) TheRedirectChainShouldBe(table *godog.Table) error {
+	var expected []redirect
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue
+		}
+		if len(row.Cells) != 2 {
+			return fmt.Errorf("table row %d has %d cells, expected a status and a location", i, len(row.Cells))
+		}
+
+		status, err := strconv.Atoi(row.Cells[0].Value)
+		if err != nil {
+			return fmt.Errorf("table row %d has an invalid status: %w", i, err)
+		}
+		location, err := f.Variables.Interpolate(row.Cells[1].Value)
+		if err != nil {
+			return err
+		}
+		expected = append(expected, redirect{status: status, location: location})
+	}
+
+	matches := len(expected) == len(f.redirects)
+	for i := 0; matches && i < len(expected); i++ {
+		matches = expected[i] == f.redirects[i]
+	}
+	if !matches {
+		return fmt.Errorf("expected the redirect chain %s, got %s", describeRedirects(expected), f.describeRedirects())
+	}
+	return nil
+}
+
+func (f *APIFeature) describeRedirects() string {
+	return describeRedirects(f.redirects)
+}
+
+// describeRedirects describes a redirect chain, e.g. "[301 /v2/datasets, 308 /datasets]"
+func describeRedirects(redirects []redirect) string {
+	if len(redirects) == 0 {
+		return "no redirects"
+	}
+
+	descriptions := make([]string, len(redirects))
+	for i, r := range redirects {
+		descriptions[i] = r.String()
+	}
+	return "[" + strings.Join(descriptions, ", ") + "]"
+}
@@ -16,6 +16,8 @@ import (
 type RedisFeature struct {
 	Server *testRedis.RedisContainer
 	Client *redis.Client
+	// Variables, when set, replaces "{{name}}" references in step arguments with scenario variables
+	Variables *ScenarioVariables
 }
 
 type RedisOptions struct {
@@ -70,10 +72,20 @@ func (r *RedisFeature) RegisterSteps(ctx *godog.ScenarioContext) {
 }
 
 func (r *RedisFeature) theKeyIsAlreadySetToAValueOfInTheRedisStore(key, value string) error {
+	key, value, err := r.interpolate(key, value)
+	if err != nil {
+		return err
+	}
+
 	return r.Client.Set(context.Background(), key, value, 0).Err()
 }
 
 func (r *RedisFeature) theKeyHasAValueOfInTheRedisStore(key, expected string) error {
+	key, expected, err := r.interpolate(key, expected)
+	if err != nil {
+		return err
+	}
+
 	actual, err := r.Client.Get(context.Background(), key).Result()
 	if err != nil {
 		return fmt.Errorf("failed to get key %q from Redis: %w", key, err)
@@ -87,6 +99,11 @@ func (r *RedisFeature) theKeyHasAValueOfInTheRedisStore(key, expected string) er
 }
 
 func (r *RedisFeature) redisContainsNoValueFor(key string) error {
+	key, err := r.Variables.Interpolate(key)
+	if err != nil {
+		return err
+	}
+
 	ctx := context.Background()
 	exists, err := r.Client.Exists(ctx, key).Result()
 	if err != nil {
@@ -103,6 +120,17 @@ func (r *RedisFeature) redisContainsNoValueFor(key string) error {
 	return nil
 }
 
+// interpolate replaces scenario variable references in a key and value
+func (r *RedisFeature) interpolate(key, value string) (interpolatedKey, interpolatedValue string, err error) {
+	if interpolatedKey, err = r.Variables.Interpolate(key); err != nil {
+		return "", "", err
+	}
+	if interpolatedValue, err = r.Variables.Interpolate(value); err != nil {
+		return "", "", err
+	}
+	return interpolatedKey, interpolatedValue, nil
+}
+
 func (r *RedisFeature) redisIsHealthy() error {
 	return r.Client.Ping(context.Background()).Err()
 }
@@ -0,0 +1,98 @@
+package componenttest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/maxcnunes/httpfake"
+)
+
+// RecordedRequest is a request received by a fake API
+type RecordedRequest struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// String describes the request by its method and URL, e.g. "PUT /instances/123?state=edition-confirmed"
+func (r RecordedRequest) String() string {
+	return r.Method + " " + r.URL.RequestURI()
+}
+
+// RequestRecorder records the requests received by a fake API, including those it has no handler for, so that
+// scenarios can assert what a service sent to it
+type RequestRecorder struct {
+	next     http.Handler
+	mu       sync.Mutex
+	requests []RecordedRequest
+}
+
+// RecordRequests starts recording the requests received by the fake and returns the recorder. Recording the
+// requests of a fake that is already being recorded returns the existing recorder.
+//
+// httpfake starts its server as soon as the fake is created, and a running server's handler cannot be replaced
+// safely, so the fake's server is restarted with the recorder in front of its handler. This gives the fake a new URL,
+// so RecordRequests should be called before the fake's URL is given to the service.
+func RecordRequests(fake *httpfake.HTTPFake) *RequestRecorder {
+	if recorder, ok := fake.Server.Config.Handler.(*RequestRecorder); ok {
+		return recorder
+	}
+
+	recorder := &RequestRecorder{next: fake.Server.Config.Handler}
+	fake.Server.Close()
+	fake.Server = httptest.NewServer(recorder)
+	return recorder
+}
+
+// ServeHTTP records the request before passing it on to the fake
+func (r *RequestRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.requests = append(r.requests, RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL,
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+	next := r.next
+	r.mu.Unlock()
+
+	next.ServeHTTP(w, req)
+}
+
+// wrap puts the handler returned by wrapper, which is given the current handler, between the recorder and the fake.
+// It is safe to call while the fake is serving requests.
+func (r *RequestRecorder) wrap(wrapper func(next http.Handler) http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.next = wrapper(r.next)
+}
+
+// Requests returns the requests received so far, in the order they were received
+func (r *RequestRecorder) Requests() []RecordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]RecordedRequest(nil), r.requests...)
+}
+
+// Reset forgets the requests received so far
+func (r *RequestRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = nil
+}
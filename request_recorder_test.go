@@ -0,0 +1,41 @@
+package componenttest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/maxcnunes/httpfake"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRecordRequests(t *testing.T) {
+	Convey("Given a fake that is not recording its requests", t, func() {
+		fake := httpfake.New()
+		defer fake.Close()
+		fake.NewHandler().Get("/ping").Reply(http.StatusOK)
+
+		Convey("When its requests are recorded", func() {
+			recorder := RecordRequests(fake)
+
+			Convey("Then requests to its new URL are recorded and still handled by the fake", func() {
+				resp, err := http.Get(fake.ResolveURL("/ping"))
+				So(err, ShouldBeNil)
+				resp.Body.Close()
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+
+				resp, err = http.Get(fake.ResolveURL("/unknown"))
+				So(err, ShouldBeNil)
+				resp.Body.Close()
+
+				requests := recorder.Requests()
+				So(requests, ShouldHaveLength, 2)
+				So(requests[0].String(), ShouldEqual, "GET /ping")
+				So(requests[1].String(), ShouldEqual, "GET /unknown")
+			})
+
+			Convey("Then recording it again returns the same recorder", func() {
+				So(RecordRequests(fake), ShouldEqual, recorder)
+			})
+		})
+	})
+}
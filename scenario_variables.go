@@ -0,0 +1,115 @@
+package componenttest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// variablePattern matches "{{name}}" references to scenario variables
+var variablePattern = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*}}`)
+
+// ScenarioVariables is a scenario scoped store of values captured by one step for use in later steps,
+// e.g. an ID returned from a POST request that is needed in a following GET request. The same store can
+// be shared between features so that a captured value can flow through a whole scenario.
+type ScenarioVariables struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewScenarioVariables returns a new, empty ScenarioVariables
+func NewScenarioVariables() *ScenarioVariables {
+	return &ScenarioVariables{
+		values: make(map[string]string),
+	}
+}
+
+// Set stores value under name, replacing any existing value. Nothing is stored by a nil ScenarioVariables.
+func (v *ScenarioVariables) Set(name, value string) {
+	if v == nil {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.values == nil {
+		v.values = make(map[string]string)
+	}
+	v.values[name] = value
+}
+
+// Get returns the value stored under name and whether it exists
+func (v *ScenarioVariables) Get(name string) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	value, ok := v.values[name]
+	return value, ok
+}
+
+// Values returns a copy of every stored value, keyed by name
+func (v *ScenarioVariables) Values() map[string]string {
+	values := make(map[string]string)
+	if v == nil {
+		return values
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	for name, value := range v.values {
+		values[name] = value
+	}
+	return values
+}
+
+// Reset removes all stored values
+func (v *ScenarioVariables) Reset() {
+	if v == nil {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.values = make(map[string]string)
+}
+
+// Interpolate replaces every "{{name}}" reference in s with its stored value. Dynamic validation
+// placeholders, e.g. "{{DYNAMIC_UUID}}", are left untouched. A reference to a variable that has not been
+// stored returns an error. Interpolate on a nil ScenarioVariables returns s unchanged.
+func (v *ScenarioVariables) Interpolate(s string) (string, error) {
+	if v == nil || !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var missing []string
+	result := variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if strings.HasPrefix(name, "DYNAMIC_") {
+			return match
+		}
+
+		value, ok := v.values[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("scenario variable(s) not set: %s", strings.Join(missing, ", "))
+	}
+
+	return result, nil
+}
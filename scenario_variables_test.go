@@ -0,0 +1,75 @@
+package componenttest
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestScenarioVariablesNilReceiver(t *testing.T) {
+	Convey("Given a nil ScenarioVariables", t, func() {
+		var v *ScenarioVariables
+
+		Convey("When it is used", func() {
+			So(func() { v.Set("datasetID", "cpih") }, ShouldNotPanic)
+			So(func() { v.Reset() }, ShouldNotPanic)
+
+			Convey("Then it has no values", func() {
+				_, ok := v.Get("datasetID")
+				So(ok, ShouldBeFalse)
+				So(v.Values(), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given an APIFeature built as a struct literal", t, func() {
+		f := &APIFeature{}
+
+		Convey("When it is Reset", func() {
+			Convey("Then it does not panic", func() {
+				So(f.Reset, ShouldNotPanic)
+			})
+		})
+	})
+}
+
+func TestScenarioVariablesInterpolate(t *testing.T) {
+	Convey("Given a ScenarioVariables with stored values", t, func() {
+		v := NewScenarioVariables()
+		v.Set("datasetID", "cpih")
+		v.Set("edition", "time-series")
+
+		tests := []struct {
+			description string
+			input       string
+			expected    string
+		}{
+			{"a string without references", "/datasets", "/datasets"},
+			{"a single reference", "/datasets/{{datasetID}}", "/datasets/cpih"},
+			{"several references", "/datasets/{{datasetID}}/editions/{{edition}}", "/datasets/cpih/editions/time-series"},
+			{"whitespace inside the braces", "/datasets/{{ datasetID }}", "/datasets/cpih"},
+			{"a dynamic placeholder", `{"id": "{{DYNAMIC_UUID}}", "dataset": "{{datasetID}}"}`, `{"id": "{{DYNAMIC_UUID}}", "dataset": "cpih"}`},
+			{"a parameterised dynamic placeholder", "{{DYNAMIC_REGEX:^[a-z]+$}}", "{{DYNAMIC_REGEX:^[a-z]+$}}"},
+		}
+
+		for _, test := range tests {
+			Convey("When "+test.description+" is interpolated", func() {
+				actual, err := v.Interpolate(test.input)
+
+				Convey("Then the references are replaced", func() {
+					So(err, ShouldBeNil)
+					So(actual, ShouldEqual, test.expected)
+				})
+			})
+		}
+
+		Convey("When a string referring to variables that have not been stored is interpolated", func() {
+			_, err := v.Interpolate("/datasets/{{datasetID}}/editions/{{missing}}/versions/{{other}}")
+
+			Convey("Then the unset variables are reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "scenario variable(s) not set: missing, other")
+			})
+		})
+	})
+}
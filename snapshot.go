@@ -0,0 +1,268 @@
+package componenttest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SnapshotStore holds the golden files that responses are compared against by the "the response should match the
+// snapshot" step, and records which of them have been used. When Update is true the golden files are rewritten from
+// the actual responses instead, so a suite can set it from its own flag or environment variable.
+type SnapshotStore struct {
+	Dir    string
+	Update bool
+	mu     sync.Mutex
+	used   map[string]bool
+	// updated holds the snapshots rewritten from actual responses
+	updated map[string]bool
+}
+
+// NewSnapshotStore returns a SnapshotStore for the golden files in dir, e.g. "features/snapshots"
+func NewSnapshotStore(dir string) *SnapshotStore {
+	return &SnapshotStore{
+		Dir:     dir,
+		used:    make(map[string]bool),
+		updated: make(map[string]bool),
+	}
+}
+
+// checkSnapshotName returns an error if name is not a plain file name, so that a snapshot cannot be read or written
+// outside of the store's directory
+func checkSnapshotName(name string) error {
+	if name == "" || name == "." || strings.Contains(name, "..") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid snapshot name %q, it must be a file name without separators or \"..\"", name)
+	}
+	return nil
+}
+
+func (s *SnapshotStore) path(name string) string {
+	return filepath.Join(s.Dir, name)
+}
+
+func (s *SnapshotStore) read(name string) ([]byte, error) {
+	s.recordUsed(name)
+
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("snapshot %q does not exist, set Update on the SnapshotStore to create it", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %q: %w", name, err)
+	}
+	return data, nil
+}
+
+func (s *SnapshotStore) write(name string, data []byte) error {
+	s.recordUsed(name)
+
+	path := s.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for snapshot %q: %w", name, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // snapshots are committed fixtures
+		return fmt.Errorf("failed to write snapshot %q: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updated[name] = true
+	return nil
+}
+
+func (s *SnapshotStore) recordUsed(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.used[filepath.ToSlash(filepath.Clean(name))] = true
+}
+
+// Snapshots returns the name of every snapshot in the store's directory, sorted
+func (s *SnapshotStore) Snapshots() ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(s.Dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		name, err := filepath.Rel(s.Dir, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(name))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots in %q: %w", s.Dir, err)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// UnusedSnapshots returns the snapshots in the store's directory that no scenario has used
+func (s *SnapshotStore) UnusedSnapshots() ([]string, error) {
+	names, err := s.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var unused []string
+	for _, name := range names {
+		if !s.used[name] {
+			unused = append(unused, name)
+		}
+	}
+	return unused, nil
+}
+
+// Report returns a summary of the snapshots that were updated and any that were never used
+func (s *SnapshotStore) Report() string {
+	var sb strings.Builder
+
+	s.mu.Lock()
+	updated := make([]string, 0, len(s.updated))
+	for name := range s.updated {
+		updated = append(updated, name)
+	}
+	s.mu.Unlock()
+
+	if len(updated) > 0 {
+		sort.Strings(updated)
+		fmt.Fprintf(&sb, "Snapshots updated: %d\n", len(updated))
+		for _, name := range updated {
+			fmt.Fprintf(&sb, "  %s\n", name)
+		}
+	}
+
+	unused, err := s.UnusedSnapshots()
+	if err != nil {
+		fmt.Fprintf(&sb, "%v\n", err)
+		return sb.String()
+	}
+	if len(unused) > 0 {
+		sb.WriteString("Snapshots never used:\n")
+		for _, name := range unused {
+			fmt.Fprintf(&sb, "  %s\n", name)
+		}
+	}
+	return sb.String()
+}
+
+// TheResponseShouldMatchTheSnapshot asserts that the response body matches the golden file name in the feature's
+// SnapshotStore. Snapshots ending ".json" are compared as JSON and may use "{{DYNAMIC_*}}" and scenario variable
+// placeholders; any other snapshot must match the body exactly. When updating snapshots, the golden file is
+// rewritten from the response, keeping any placeholders that the response still satisfies.
+func (f *APIFeature) TheResponseShouldMatchTheSnapshot(name string) error {
+	if f.Snapshots == nil {
+		return errors.New("no SnapshotStore has been set on the APIFeature")
+	}
+	if err := checkSnapshotName(name); err != nil {
+		return err
+	}
+
+	body, err := f.readResponseBody()
+	if err != nil {
+		return err
+	}
+
+	isJSON := strings.HasSuffix(name, ".json")
+
+	if f.Snapshots.Update {
+		if isJSON {
+			if body, err = f.jsonSnapshot(name, body); err != nil {
+				return err
+			}
+		}
+		return f.Snapshots.write(name, body)
+	}
+
+	snapshot, err := f.Snapshots.read(name)
+	if err != nil {
+		return err
+	}
+
+	if isJSON {
+		if err := f.assertJSONResponse(string(snapshot), jsonComparison{}); err != nil {
+			return fmt.Errorf("snapshot %q: %w", name, err)
+		}
+		return nil
+	}
+
+	if string(body) != string(snapshot) {
+		return fmt.Errorf("response body does not match snapshot %q:\nexpected: %q\nactual:   %q", name, snapshot, body)
+	}
+	return nil
+}
+
+// jsonSnapshot returns the pretty-printed response body to write to a JSON snapshot, keeping the placeholders
+// of any existing snapshot
+func (f *APIFeature) jsonSnapshot(name string, body []byte) ([]byte, error) {
+	var actual interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body for snapshot %q: %w", name, err)
+	}
+
+	if existing, err := os.ReadFile(f.Snapshots.path(name)); err == nil {
+		var previous interface{}
+		if err := json.Unmarshal(existing, &previous); err == nil {
+			actual = f.keepSnapshotPlaceholders(previous, actual)
+		}
+	}
+
+	snapshot, err := json.MarshalIndent(actual, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot %q: %w", name, err)
+	}
+	return append(snapshot, '\n'), nil
+}
+
+// keepSnapshotPlaceholders returns actual with each placeholder of the previous snapshot put back wherever the
+// actual value still satisfies it, so that updating a snapshot does not replace placeholders with volatile values
+func (f *APIFeature) keepSnapshotPlaceholders(previous, actual interface{}) interface{} {
+	switch prev := previous.(type) {
+	case string:
+		if !strings.Contains(prev, "{{") {
+			return actual
+		}
+		if strings.HasPrefix(prev, "{{DYNAMIC_") {
+			if f.dynamicValidatorRegistry().validateDynamicValue(actual, prev, "") == nil {
+				return prev
+			}
+			return actual
+		}
+		if interpolated, err := f.Variables.Interpolate(prev); err == nil && interpolated == actual {
+			return prev
+		}
+	case map[string]interface{}:
+		if act, ok := actual.(map[string]interface{}); ok {
+			for key, actValue := range act {
+				if prevValue, exists := prev[key]; exists {
+					act[key] = f.keepSnapshotPlaceholders(prevValue, actValue)
+				}
+			}
+		}
+	case []interface{}:
+		if act, ok := actual.([]interface{}); ok {
+			for i := range act {
+				if i < len(prev) {
+					act[i] = f.keepSnapshotPlaceholders(prev[i], act[i])
+				}
+			}
+		}
+	}
+	return actual
+}
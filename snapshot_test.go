@@ -0,0 +1,49 @@
+package componenttest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTheResponseShouldMatchTheSnapshot(t *testing.T) {
+	Convey("Given an APIFeature with a snapshot store that is being updated", t, func() {
+		parent := t.TempDir()
+		store := NewSnapshotStore(filepath.Join(parent, "snapshots"))
+		store.Update = true
+		f := &APIFeature{
+			Snapshots:    store,
+			HTTPResponse: &http.Response{Body: io.NopCloser(strings.NewReader("body"))},
+		}
+
+		for _, name := range []string{"../escaped.txt", "nested/snapshot.txt", `nested\snapshot.txt`, "..", ""} {
+			Convey("When the response is matched to the snapshot "+name, func() {
+				err := f.TheResponseShouldMatchTheSnapshot(name)
+
+				Convey("Then the name is rejected and nothing is written", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldStartWith, "invalid snapshot name")
+
+					_, err := os.Stat(filepath.Join(parent, "escaped.txt"))
+					So(os.IsNotExist(err), ShouldBeTrue)
+				})
+			})
+		}
+
+		Convey("When the response is matched to a snapshot with a plain file name", func() {
+			err := f.TheResponseShouldMatchTheSnapshot("snapshot.txt")
+
+			Convey("Then the snapshot is written to the store's directory", func() {
+				So(err, ShouldBeNil)
+				data, err := os.ReadFile(filepath.Join(parent, "snapshots", "snapshot.txt"))
+				So(err, ShouldBeNil)
+				So(string(data), ShouldEqual, "body")
+			})
+		})
+	})
+}
@@ -0,0 +1,611 @@
+package componenttest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cucumber/godog"
+)
+
+// streamFlushGap is how long a connection to a running service must stay open after data arrives for that data to
+// count as flushed before the stream completed
+const streamFlushGap = 10 * time.Millisecond
+
+// responseStream is a response body that is received incrementally, while the handler or service is still writing it
+type responseStream struct {
+	mu sync.Mutex
+	// changed is closed and replaced whenever the stream changes, to wake any waiting step
+	changed  chan struct{}
+	header   http.Header
+	status   int
+	received bool
+	data     []byte
+	flushed  bool
+	done     bool
+	err      error
+	// lastData is when data last arrived from a running service
+	lastData time.Time
+	cancel   context.CancelFunc
+	// timeout is how long to wait for the response headers, and for more of the body when it is read in full
+	timeout time.Duration
+	// linesRead and eventsRead are the number of lines and events already asserted on
+	linesRead  int
+	eventsRead int
+}
+
+// streamEvent is a Server-Sent Event
+type streamEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+func newResponseStream(cancel context.CancelFunc, timeout time.Duration) *responseStream {
+	return &responseStream{
+		changed: make(chan struct{}),
+		header:  make(http.Header),
+		cancel:  cancel,
+		timeout: timeout,
+	}
+}
+
+// update changes the stream while holding its lock and then wakes any waiting step
+func (s *responseStream) update(change func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change()
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// wait blocks until condition, which is called while holding the stream's lock, returns true or the deadline passes.
+// It reports whether the condition was met.
+func (s *responseStream) wait(deadline time.Time, condition func() bool) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		met, changed := condition(), s.changed
+		s.mu.Unlock()
+
+		if met {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return condition()
+		}
+	}
+}
+
+// finish marks the stream as complete, with any error that ended it
+func (s *responseStream) finish(err error) {
+	s.update(func() {
+		if !s.lastData.IsZero() && time.Since(s.lastData) >= streamFlushGap {
+			s.flushed = true
+		}
+		s.done = true
+		s.err = err
+	})
+}
+
+// lines returns the complete lines received so far, and the final unterminated line once the stream is done
+func (s *responseStream) lines() []string {
+	content := string(s.data)
+	if !s.done {
+		content = content[:strings.LastIndex(content, "\n")+1]
+	}
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// events returns the complete Server-Sent Events received so far. Comments and events without data are ignored.
+func (s *responseStream) events() []streamEvent {
+	var events []streamEvent
+
+	current, data := streamEvent{}, []string{}
+	for _, line := range s.lines() {
+		if line == "" {
+			if len(data) > 0 {
+				current.data = strings.Join(data, "\n")
+				if current.event == "" {
+					current.event = "message"
+				}
+				events = append(events, current)
+			}
+			current, data = streamEvent{}, []string{}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			current.event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			current.id = value
+		}
+	}
+	return events
+}
+
+// streamBody is a response body that reads the stream as it is received, returning io.EOF once it is complete
+type streamBody struct {
+	stream *responseStream
+	offset int
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	var n int
+	var err error
+	b.stream.wait(time.Now().Add(b.stream.timeout), func() bool {
+		if b.offset < len(b.stream.data) {
+			n = copy(p, b.stream.data[b.offset:])
+			b.offset += n
+			return true
+		}
+		if b.stream.done {
+			err = b.stream.err
+			if err == nil {
+				err = io.EOF
+			}
+			return true
+		}
+		return false
+	})
+	if n == 0 && err == nil {
+		err = fmt.Errorf("timed out after %v waiting for the stream", b.stream.timeout)
+	}
+	return n, err
+}
+
+func (b *streamBody) Close() error {
+	return nil
+}
+
+// streamRecorder is a http.ResponseWriter and http.Flusher that makes written data available to the stream only
+// when it is flushed or the handler returns, as a real server would
+type streamRecorder struct {
+	stream  *responseStream
+	header  http.Header
+	pending bytes.Buffer
+}
+
+func (r *streamRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *streamRecorder) WriteHeader(status int) {
+	r.stream.update(func() {
+		r.writeHeader(status)
+	})
+}
+
+// writeHeader records the status and headers, if not already recorded, and must be called while holding the
+// stream's lock
+func (r *streamRecorder) writeHeader(status int) {
+	if r.stream.received {
+		return
+	}
+	r.stream.status = status
+	r.stream.header = r.header.Clone()
+	r.stream.received = true
+}
+
+func (r *streamRecorder) Write(p []byte) (int, error) {
+	r.stream.update(func() {
+		r.writeHeader(http.StatusOK)
+	})
+	return r.pending.Write(p)
+}
+
+func (r *streamRecorder) Flush() {
+	r.stream.update(func() {
+		r.writeHeader(http.StatusOK)
+		if r.pending.Len() > 0 {
+			r.stream.data = append(r.stream.data, r.pending.Bytes()...)
+			r.stream.flushed = true
+			r.pending.Reset()
+		}
+	})
+}
+
+// fail ends the stream with err, discarding any unflushed data as a real server would when its handler panics
+func (r *streamRecorder) fail(err error) {
+	r.stream.update(func() {
+		r.writeHeader(http.StatusInternalServerError)
+		r.stream.done = true
+		r.stream.err = err
+	})
+}
+
+// complete makes any unflushed data available and marks the stream as done
+func (r *streamRecorder) complete() {
+	r.stream.update(func() {
+		r.writeHeader(http.StatusOK)
+		r.stream.data = append(r.stream.data, r.pending.Bytes()...)
+		r.stream.done = true
+	})
+}
+
+// IGetAsAStream makes a GET request to the provided path with the current headers and returns once the response
+// headers are received, leaving the body to be received in the background. The body can then be asserted on as it
+// arrives with the stream steps, or read in full by the other response steps, which wait for the stream to complete.
+// Streamed responses are not decoded or validated against the OpenAPI contract, and redirects are not followed.
+func (f *APIFeature) IGetAsAStream(path string) error {
+	f.closeStream()
+
+	path, err := f.Variables.Interpolate(path)
+	if err != nil {
+		return err
+	}
+
+	req, err := f.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req = req.WithContext(ctx)
+	stream := newResponseStream(cancel, f.clientTimeout())
+
+	if f.BaseURL == "" {
+		err = f.serveStream(req, stream)
+	} else {
+		err = f.sendStream(req, stream)
+	}
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	if !stream.wait(time.Now().Add(stream.timeout), func() bool { return stream.received || stream.done }) {
+		cancel()
+		return fmt.Errorf("timed out after %v waiting for the response headers of the stream", stream.timeout)
+	}
+
+	stream.mu.Lock()
+	resp := &http.Response{
+		Status:     fmt.Sprintf("%d %s", stream.status, http.StatusText(stream.status)),
+		StatusCode: stream.status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     stream.header,
+		Body:       &streamBody{stream: stream},
+		Request:    req,
+	}
+	stream.mu.Unlock()
+
+	f.stream = stream
+	f.HTTPResponse = resp
+	f.redirects = nil
+	f.responseEncoding = responseEncoding{}
+	return f.storeResponseCookies(req, resp)
+}
+
+// serveStream serves req with the Initialiser's handler in the background
+func (f *APIFeature) serveStream(req *http.Request, stream *responseStream) error {
+	handler, err := f.Initialiser()
+	if err != nil {
+		return err
+	}
+
+	recorder := &streamRecorder{stream: stream, header: make(http.Header)}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				recorder.fail(fmt.Errorf("handler panicked: %v", r))
+				return
+			}
+			recorder.complete()
+		}()
+		handler.ServeHTTP(recorder, req)
+	}()
+	return nil
+}
+
+// sendStream sends req to the running service and receives the response body in the background. The client's
+// Timeout only limits how long the response headers take, as it would otherwise cut off a stream that lasts longer;
+// the body is limited by the deadlines of the stream steps instead.
+func (f *APIFeature) sendStream(req *http.Request, stream *responseStream) error {
+	client := *f.HTTPClient
+	client.Timeout = 0
+
+	timer := time.AfterFunc(stream.timeout, stream.cancel)
+
+	resp, err := client.Do(req)
+	if !timer.Stop() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		return fmt.Errorf("timed out after %v waiting for the response headers of the stream from %s", stream.timeout, req.URL)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to make %s request to %s: %w", req.Method, req.URL, err)
+	}
+
+	stream.update(func() {
+		stream.status = resp.StatusCode
+		stream.header = resp.Header
+		stream.received = true
+	})
+
+	go func() {
+		defer resp.Body.Close()
+
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := resp.Body.Read(buf)
+			if n > 0 {
+				stream.update(func() {
+					if !stream.lastData.IsZero() && time.Since(stream.lastData) >= streamFlushGap {
+						stream.flushed = true
+					}
+					stream.data = append(stream.data, buf[:n]...)
+					stream.lastData = time.Now()
+				})
+			}
+			if errors.Is(err, io.EOF) {
+				stream.finish(nil)
+				return
+			}
+			if err != nil {
+				stream.finish(fmt.Errorf("error reading stream: %w", err))
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// clientTimeout returns the timeout of the feature's HTTP client, or the default timeout for an in-process handler or
+// a client without one
+func (f *APIFeature) clientTimeout() time.Duration {
+	if f.HTTPClient != nil && f.HTTPClient.Timeout > 0 {
+		return f.HTTPClient.Timeout
+	}
+	return defaultAPIClientTimeout
+}
+
+// closeStream stops receiving any stream in progress
+func (f *APIFeature) closeStream() {
+	if f.stream != nil {
+		f.stream.cancel()
+		f.stream = nil
+	}
+}
+
+func (f *APIFeature) currentStream() (*responseStream, error) {
+	if f.stream == nil {
+		return nil, errors.New("no stream has been requested")
+	}
+	return f.stream, nil
+}
+
+// IShouldReceiveTheFollowingLinesWithin asserts that the next lines of the stream, e.g. of NDJSON or CSV, are the
+// lines of the body and that they are received within the timeout
+func (f *APIFeature) IShouldReceiveTheFollowingLinesWithin(timeoutSeconds int, body *godog.DocString) error {
+	stream, err := f.currentStream()
+	if err != nil {
+		return err
+	}
+
+	content, err := f.Variables.Interpolate(body.Content)
+	if err != nil {
+		return err
+	}
+	expected := strings.Split(strings.TrimSpace(content), "\n")
+	for i, line := range expected {
+		expected[i] = strings.TrimSpace(line)
+	}
+
+	var received []string
+	stream.wait(time.Now().Add(time.Duration(timeoutSeconds)*time.Second), func() bool {
+		received = stream.lines()[stream.linesRead:]
+		return len(received) >= len(expected) || stream.done
+	})
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	for i, line := range expected {
+		if i >= len(received) {
+			return fmt.Errorf("expected line %d of the stream to be %q within %d seconds, but %s", stream.linesRead+i+1, line, timeoutSeconds, stream.describeEnd())
+		}
+		if received[i] != line {
+			return fmt.Errorf("expected line %d of the stream to be %q, got %q", stream.linesRead+i+1, line, received[i])
+		}
+	}
+
+	stream.linesRead += len(expected)
+	return nil
+}
+
+/*
+IShouldReceiveTheFollowingEventsWithin asserts that the next Server-Sent Events of the stream are the events in the
+table, in order, and that they are received within the timeout. The table can have event, data and id columns, and
+columns that are not in the table are not checked.
+
+Table should look like:
+| event    | data            |
+| progress | {"percent": 50} |
+| done     | {}              |
+*/
+func (f *APIFeature) IShouldReceiveTheFollowingEventsWithin(timeoutSeconds int, table *godog.Table) error {
+	stream, err := f.currentStream()
+	if err != nil {
+		return err
+	}
+
+	expected, columns, err := f.tableEvents(table)
+	if err != nil {
+		return err
+	}
+
+	var received []streamEvent
+	stream.wait(time.Now().Add(time.Duration(timeoutSeconds)*time.Second), func() bool {
+		received = stream.events()[stream.eventsRead:]
+		return len(received) >= len(expected) || stream.done
+	})
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	for i, event := range expected {
+		if i >= len(received) {
+			return fmt.Errorf("expected event %d of the stream to be %s within %d seconds, but %s", stream.eventsRead+i+1, event.describe(columns), timeoutSeconds, stream.describeEnd())
+		}
+		if !event.matches(received[i], columns) {
+			return fmt.Errorf("expected event %d of the stream to be %s, got %s", stream.eventsRead+i+1, event.describe(columns), received[i].describe(columns))
+		}
+	}
+
+	stream.eventsRead += len(expected)
+	return nil
+}
+
+// tableEvents returns the events in a table and the columns they have, which are named by the table's header row
+func (f *APIFeature) tableEvents(table *godog.Table) ([]streamEvent, []string, error) {
+	if len(table.Rows) == 0 {
+		return nil, nil, errors.New("the table of events is empty")
+	}
+
+	var columns []string
+	for _, cell := range table.Rows[0].Cells {
+		switch cell.Value {
+		case "event", "data", "id":
+			columns = append(columns, cell.Value)
+		default:
+			return nil, nil, fmt.Errorf("unknown event column %q, expected event, data or id", cell.Value)
+		}
+	}
+
+	var events []streamEvent
+	for i, row := range table.Rows[1:] {
+		if len(row.Cells) != len(columns) {
+			return nil, nil, fmt.Errorf("table row %d has %d cells, expected %d", i+1, len(row.Cells), len(columns))
+		}
+
+		var event streamEvent
+		for j, cell := range row.Cells {
+			value, err := f.Variables.Interpolate(cell.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch columns[j] {
+			case "event":
+				event.event = value
+			case "data":
+				event.data = value
+			case "id":
+				event.id = value
+			}
+		}
+		events = append(events, event)
+	}
+	return events, columns, nil
+}
+
+func (e streamEvent) matches(actual streamEvent, columns []string) bool {
+	for _, column := range columns {
+		if e.field(column) != actual.field(column) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e streamEvent) field(column string) string {
+	switch column {
+	case "event":
+		return e.event
+	case "data":
+		return e.data
+	default:
+		return e.id
+	}
+}
+
+// describe describes the columns of the event, e.g. `event "progress" with data "{}"`
+func (e streamEvent) describe(columns []string) string {
+	descriptions := make([]string, len(columns))
+	for i, column := range columns {
+		descriptions[i] = fmt.Sprintf("%s %q", column, e.field(column))
+	}
+	return strings.Join(descriptions, " with ")
+}
+
+// describeEnd describes why no more data was asserted on, and must be called while holding the stream's lock
+func (s *responseStream) describeEnd() string {
+	switch {
+	case s.err != nil:
+		return fmt.Sprintf("the stream failed: %v", s.err)
+	case s.done:
+		return "the stream completed first"
+	default:
+		return "it was not received"
+	}
+}
+
+// TheStreamShouldCompleteWithin asserts that the stream is complete within the timeout
+func (f *APIFeature) TheStreamShouldCompleteWithin(timeoutSeconds int) error {
+	stream, err := f.currentStream()
+	if err != nil {
+		return err
+	}
+
+	if !stream.wait(time.Now().Add(time.Duration(timeoutSeconds)*time.Second), func() bool { return stream.done }) {
+		return fmt.Errorf("the stream did not complete within %d seconds", timeoutSeconds)
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	return stream.err
+}
+
+// TheStreamShouldHaveBeenFlushedBeforeItCompleted asserts that data was flushed to the client while the stream was
+// still open, i.e. the handler called http.Flusher. It waits for the stream to complete. Over a real connection, a
+// flush is only detected if the stream stays open briefly after it.
+func (f *APIFeature) TheStreamShouldHaveBeenFlushedBeforeItCompleted() error {
+	stream, err := f.currentStream()
+	if err != nil {
+		return err
+	}
+
+	if !stream.wait(time.Now().Add(stream.timeout), func() bool { return stream.done }) {
+		return fmt.Errorf("the stream did not complete within %v", stream.timeout)
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	if !stream.flushed {
+		return errors.New("the stream was not flushed before it completed, so its data was only received at the end")
+	}
+	return nil
+}
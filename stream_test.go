@@ -0,0 +1,121 @@
+package componenttest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cucumber/godog"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIGetAsAStreamWithBaseURL(t *testing.T) {
+	Convey("Given a running service that streams for longer than the client timeout", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			for i := 1; i <= 3; i++ {
+				fmt.Fprintf(w, "{\"line\": %d}\n", i)
+				w.(http.Flusher).Flush()
+				time.Sleep(100 * time.Millisecond)
+			}
+		}))
+		defer server.Close()
+
+		f := NewAPIFeatureWithBaseURL(server.URL, &APIClientOptions{Timeout: 150 * time.Millisecond})
+		defer f.Reset()
+
+		Convey("When the stream is requested", func() {
+			err := f.IGetAsAStream("/")
+			So(err, ShouldBeNil)
+
+			Convey("Then every line is received", func() {
+				So(f.IShouldReceiveTheFollowingLinesWithin(2, &godog.DocString{Content: "{\"line\": 1}\n{\"line\": 2}\n{\"line\": 3}"}), ShouldBeNil)
+				So(f.TheStreamShouldCompleteWithin(2), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a running service that does not send the response headers within the client timeout", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+
+		f := NewAPIFeatureWithBaseURL(server.URL, &APIClientOptions{Timeout: 100 * time.Millisecond})
+		defer f.Reset()
+
+		Convey("When the stream is requested", func() {
+			err := f.IGetAsAStream("/")
+
+			Convey("Then the step times out", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "timed out after 100ms waiting for the response headers")
+			})
+		})
+	})
+}
+
+func TestIGetAsAStreamTimeouts(t *testing.T) {
+	Convey("Given a running service that stalls part way through a stream", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, "first")
+			w.(http.Flusher).Flush()
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+
+		f := NewAPIFeatureWithBaseURL(server.URL, &APIClientOptions{Timeout: 100 * time.Millisecond})
+		defer f.Reset()
+		So(f.IGetAsAStream("/"), ShouldBeNil)
+
+		Convey("When the whole body is read", func() {
+			_, err := io.ReadAll(f.HTTPResponse.Body)
+
+			Convey("Then reading times out after the client timeout", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "timed out after 100ms waiting for the stream")
+			})
+		})
+
+		Convey("When the stream is asserted to have been flushed before it completed", func() {
+			err := f.TheStreamShouldHaveBeenFlushedBeforeItCompleted()
+
+			Convey("Then waiting for it to complete times out after the client timeout", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "the stream did not complete within 100ms")
+			})
+		})
+	})
+}
+
+func TestIGetAsAStreamWithPanickingHandler(t *testing.T) {
+	Convey("Given an in-process handler that panics after flushing part of a stream", t, func() {
+		f := NewAPIFeatureWithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintln(w, "first")
+			w.(http.Flusher).Flush()
+			fmt.Fprintln(w, "unflushed")
+			panic("stream broke")
+		}))
+		defer f.Reset()
+
+		Convey("When the stream is requested and read in full", func() {
+			So(f.IGetAsAStream("/"), ShouldBeNil)
+			body, err := io.ReadAll(f.HTTPResponse.Body)
+
+			Convey("Then the flushed data is received and the panic is reported as a stream error", func() {
+				So(string(body), ShouldEqual, "first\n")
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "handler panicked: stream broke")
+			})
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"math"
 	"net/url"
 	"time"
 
@@ -13,14 +14,18 @@ func ValidateTimestamp(value string) bool {
 }
 
 func ValidateRecentTimestamp(value string) bool {
+	// Ensure the timestamp is within 10 seconds of now
+	return ValidateRecentTimestampWithin(value, 10*time.Second)
+}
+
+func ValidateRecentTimestampWithin(value string, maxAge time.Duration) bool {
 	parsedTime, err := time.Parse(time.RFC3339, value)
 	if err != nil {
 		return false
 	}
 
-	// Ensure the timestamp is within 10 seconds of now
 	timestampAge := time.Since(parsedTime)
-	if timestampAge < 0 || timestampAge > 10*time.Second {
+	if timestampAge < 0 || timestampAge > maxAge {
 		return false
 	}
 
@@ -60,3 +65,42 @@ func ValidateURIPath(value string) bool {
 
 	return true
 }
+
+func ValidateOneOf(value string, options []string) bool {
+	for _, option := range options {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
+
+// The following validators accept any JSON value, as decoded by encoding/json into an interface{}
+
+func ValidateAny(_ interface{}) bool {
+	return true
+}
+
+func ValidateNotNull(value interface{}) bool {
+	return value != nil
+}
+
+func ValidateNumber(value interface{}) bool {
+	_, ok := value.(float64)
+	return ok
+}
+
+func ValidateInt(value interface{}) bool {
+	number, ok := value.(float64)
+	return ok && number == math.Trunc(number) && !math.IsInf(number, 0)
+}
+
+func ValidateNumberInRange(value interface{}, minimum, maximum float64) bool {
+	number, ok := value.(float64)
+	return ok && number >= minimum && number <= maximum
+}
+
+func ValidateArrayLength(value interface{}, length int) bool {
+	array, ok := value.([]interface{})
+	return ok && len(array) == length
+}
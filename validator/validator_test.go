@@ -152,3 +152,197 @@ func TestValidateURIPath(t *testing.T) {
 		})
 	})
 }
+
+func TestValidateRecentTimestampWithin(t *testing.T) {
+	Convey("Given a timestamp string from 30 seconds ago", t, func() {
+		ts := time.Now().Add(-30 * time.Second).Format(time.RFC3339)
+
+		Convey("When it is validated with a maximum age of 60 seconds", func() {
+			valid := ValidateRecentTimestampWithin(ts, 60*time.Second)
+
+			Convey("Then it is valid", func() {
+				So(valid, ShouldBeTrue)
+			})
+		})
+
+		Convey("When it is validated with a maximum age of 10 seconds", func() {
+			valid := ValidateRecentTimestampWithin(ts, 10*time.Second)
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given an invalid timestamp string", t, func() {
+		ts := invalidString
+
+		Convey("When it is validated", func() {
+			valid := ValidateRecentTimestampWithin(ts, time.Minute)
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestValidateOneOf(t *testing.T) {
+	options := []string{"published", "associated", "created"}
+
+	Convey("Given a value that is one of the options", t, func() {
+		value := "associated"
+
+		Convey("When it is validated", func() {
+			valid := ValidateOneOf(value, options)
+
+			Convey("Then it is valid", func() {
+				So(valid, ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given a value that is not one of the options", t, func() {
+		value := invalidString
+
+		Convey("When it is validated", func() {
+			valid := ValidateOneOf(value, options)
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestValidateNotNull(t *testing.T) {
+	Convey("Given a non-null value", t, func() {
+		value := map[string]interface{}{}
+
+		Convey("When it is validated", func() {
+			valid := ValidateNotNull(value)
+
+			Convey("Then it is valid", func() {
+				So(valid, ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given a null value", t, func() {
+		Convey("When it is validated", func() {
+			valid := ValidateNotNull(nil)
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestValidateNumber(t *testing.T) {
+	Convey("Given a number", t, func() {
+		Convey("When it is validated", func() {
+			valid := ValidateNumber(1.5)
+
+			Convey("Then it is valid", func() {
+				So(valid, ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given a string", t, func() {
+		Convey("When it is validated", func() {
+			valid := ValidateNumber("1")
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestValidateInt(t *testing.T) {
+	Convey("Given a whole number", t, func() {
+		Convey("When it is validated", func() {
+			valid := ValidateInt(float64(42))
+
+			Convey("Then it is valid", func() {
+				So(valid, ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given a fractional number", t, func() {
+		Convey("When it is validated", func() {
+			valid := ValidateInt(42.5)
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given a string", t, func() {
+		Convey("When it is validated", func() {
+			valid := ValidateInt("42")
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestValidateNumberInRange(t *testing.T) {
+	Convey("Given a number within the range", t, func() {
+		Convey("When it is validated", func() {
+			valid := ValidateNumberInRange(float64(100), 1, 100)
+
+			Convey("Then it is valid", func() {
+				So(valid, ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given a number outside of the range", t, func() {
+		Convey("When it is validated", func() {
+			valid := ValidateNumberInRange(float64(0), 1, 100)
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestValidateArrayLength(t *testing.T) {
+	Convey("Given an array of two elements", t, func() {
+		array := []interface{}{"a", "b"}
+
+		Convey("When it is validated against a length of 2", func() {
+			valid := ValidateArrayLength(array, 2)
+
+			Convey("Then it is valid", func() {
+				So(valid, ShouldBeTrue)
+			})
+		})
+
+		Convey("When it is validated against a length of 3", func() {
+			valid := ValidateArrayLength(array, 3)
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given an object", t, func() {
+		Convey("When it is validated", func() {
+			valid := ValidateArrayLength(map[string]interface{}{}, 0)
+
+			Convey("Then it is invalid", func() {
+				So(valid, ShouldBeFalse)
+			})
+		})
+	})
+}